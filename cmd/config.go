@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"dnsfailover/internal/audit"
+	"dnsfailover/internal/config"
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/storage"
+	"dnsfailover/internal/webhook"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyLimit int
+	exportFormat string
+	exportOutput string
+	importFormat string
+	importMode   string
+	importDryRun bool
+
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "配置管理命令",
+		Long:  "查看配置版本历史、比较版本差异、回滚、导入导出配置。运行中的监控服务会在数秒内自动应用新的配置版本",
+	}
+
+	configHistoryCmd = &cobra.Command{
+		Use:   "history",
+		Short: "列出配置版本",
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+
+			revisions, err := store.GetConfigRevisions(historyLimit)
+			if err != nil {
+				exitWithError(err)
+			}
+			if len(revisions) == 0 {
+				fmt.Println("暂无配置版本")
+				return
+			}
+			for _, rev := range revisions {
+				fmt.Printf("#%-6d %s  %-20s %s\n", rev.ID, rev.CreatedAt, rev.Author, rev.Comment)
+			}
+		},
+	}
+
+	configShowCmd = &cobra.Command{
+		Use:   "show <revision>",
+		Short: "显示指定版本的配置内容",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+			rev := mustLoadRevision(store, args[0])
+
+			data, _ := json.MarshalIndent(rev.Config, "", "  ")
+			fmt.Printf("# 版本 #%d  %s  %s %s\n", rev.ID, rev.CreatedAt, rev.Author, rev.Comment)
+			fmt.Println(string(data))
+		},
+	}
+
+	configDiffCmd = &cobra.Command{
+		Use:   "diff <from> <to>",
+		Short: "比较两个配置版本",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+			from := mustLoadRevision(store, args[0])
+			to := mustLoadRevision(store, args[1])
+
+			printChanges(audit.Diff(from.Config, to.Config))
+		},
+	}
+
+	configRollbackCmd = &cobra.Command{
+		Use:   "rollback <revision>",
+		Short: "回滚到指定配置版本",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+			rev := mustLoadRevision(store, args[0])
+
+			before, _ := store.LoadConfig()
+			if before != nil {
+				// 版本中的 Webhook 密钥为空或为占位符时保留当前密钥
+				rev.Config.Webhook.Secret = config.KeepSecret(rev.Config.Webhook.Secret, before.Webhook.Secret)
+			}
+
+			// 旧版本可能不满足之后新增的校验规则，校验失败时拒绝回滚
+			if err := config.NormalizeFullConfig(rev.Config); err != nil {
+				exitWithError(fmt.Errorf("版本 #%d 的配置校验失败，无法回滚: %w", rev.ID, err))
+			}
+			if err := webhook.ValidateTemplate(rev.Config.Webhook.Template); err != nil {
+				exitWithError(fmt.Errorf("版本 #%d 的配置校验失败，无法回滚: %w", rev.ID, err))
+			}
+
+			revision, err := store.SaveConfig(rev.Config, cliActor(), fmt.Sprintf("回滚到版本 #%d", rev.ID))
+			if err != nil {
+				exitWithError(err)
+			}
+			recordCLIAudit("config rollback", fmt.Sprintf("回滚配置到版本 #%d", rev.ID),
+				config.RedactFullConfig(before), config.RedactFullConfig(rev.Config))
+
+			fmt.Printf("已回滚到版本 #%d，生成新版本 #%d\n", rev.ID, revision)
+		},
+	}
+
+	configExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "导出配置与定时任务",
+		Long:  "将探针配置、监控目标、Webhook 设置及定时任务导出为 YAML 或 JSON，便于纳入版本管理或复制到其他节点",
+		Run: func(cmd *cobra.Command, args []string) {
+			if exportOutput == "" {
+				logger.ConsoleOutput = os.Stderr
+			}
+			store := mustInitStorage()
+
+			doc, err := config.ExportDocument(store)
+			if err != nil {
+				exitWithError(err)
+			}
+			doc.ExportedAt = time.Now().Format("2006-01-02 15:04:05")
+
+			format := exportFormat
+			if format == "" {
+				format = config.FormatFromPath(exportOutput)
+			}
+			data, err := config.EncodeDocument(doc, format)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			if exportOutput == "" {
+				os.Stdout.Write(data)
+				return
+			}
+			if err := os.WriteFile(exportOutput, data, 0600); err != nil {
+				exitWithError(fmt.Errorf("写入文件失败: %w", err))
+			}
+			fmt.Printf("配置已导出到 %s\n", exportOutput)
+		},
+	}
+
+	configImportCmd = &cobra.Command{
+		Use:   "import <file>",
+		Short: "从文件导入配置与定时任务",
+		Long: `从 YAML 或 JSON 文件导入配置与定时任务，校验规则与 Web 面板保存配置一致。
+  merge   文件中出现的字段覆盖现有值，目标列表取并集，文件中没有的定时任务保留
+  replace 文件中出现的部分整体替换，文件中没有的定时任务将被删除
+文件中未包含的部分（config 或 schedules）在两种模式下均保持不变`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			mode, err := config.ParseImportMode(importMode)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				exitWithError(fmt.Errorf("读取文件失败: %w", err))
+			}
+			format := importFormat
+			if format == "" {
+				format = config.FormatFromPath(args[0])
+			}
+
+			store := mustInitStorage()
+			plan, err := config.PlanImport(store, data, format, mode)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			printImportPlan(plan)
+			if importDryRun {
+				fmt.Println("(dry-run，未写入任何变更)")
+				return
+			}
+			if plan.Empty() {
+				return
+			}
+
+			before, _ := config.ExportDocument(store)
+			revision, err := plan.Apply(store, cliActor())
+			if err != nil {
+				exitWithError(err)
+			}
+			after, _ := config.ExportDocument(store)
+			recordCLIAudit("config import", fmt.Sprintf("导入配置 (%s)", mode), config.RedactDocument(before), config.RedactDocument(after))
+
+			if revision > 0 {
+				fmt.Printf("导入完成，生成新版本 #%d\n", revision)
+			} else {
+				fmt.Println("导入完成")
+			}
+		},
+	}
+)
+
+// mustLoadRevision 读取配置版本，失败时退出
+func mustLoadRevision(store *storage.Storage, idStr string) *storage.ConfigRevision {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		exitWithError(fmt.Errorf("无效的版本号: %s", idStr))
+	}
+
+	rev, err := store.GetConfigRevision(id)
+	if err != nil {
+		exitWithError(err)
+	}
+	if rev == nil {
+		exitWithError(fmt.Errorf("配置版本不存在: #%d", id))
+	}
+	return rev
+}
+
+// printChanges 打印字段级变更
+func printChanges(changes []audit.Change) {
+	if len(changes) == 0 {
+		fmt.Println("无差异")
+		return
+	}
+	for _, c := range changes {
+		before, _ := json.Marshal(c.Before)
+		after, _ := json.Marshal(c.After)
+		fmt.Printf("%s\n  - %s\n  + %s\n", c.Path, before, after)
+	}
+}
+
+// printImportPlan 打印导入计划
+func printImportPlan(plan *config.ImportPlan) {
+	if plan.Empty() {
+		fmt.Println("无差异")
+		return
+	}
+
+	if len(plan.ConfigChanges) > 0 {
+		fmt.Println("== 配置 ==")
+		printChanges(plan.ConfigChanges)
+	}
+
+	if len(plan.TaskChanges) > 0 {
+		fmt.Println("== 定时任务 ==")
+		for _, c := range plan.TaskChanges {
+			switch c.Action {
+			case config.TaskAdd:
+				fmt.Printf("+ 新增 %s (%s %s)\n", c.Task.Name, c.Task.Cron, c.Task.Target)
+			case config.TaskDelete:
+				fmt.Printf("- 删除 %s (%s)\n", c.Task.Name, c.Task.ID)
+			case config.TaskUpdate:
+				fmt.Printf("~ 更新 %s (%s)\n", c.Task.Name, c.Task.ID)
+				printChanges(c.Changes)
+			}
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configHistoryCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configDiffCmd)
+	configCmd.AddCommand(configRollbackCmd)
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+
+	configHistoryCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "显示的版本数量")
+	configExportCmd.Flags().StringVarP(&exportFormat, "format", "f", "", "导出格式 yaml/json（默认根据输出文件扩展名判断，否则为 yaml）")
+	configExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "输出文件（默认输出到标准输出）")
+	configImportCmd.Flags().StringVarP(&importFormat, "format", "f", "", "文件格式 yaml/json（默认根据扩展名判断）")
+	configImportCmd.Flags().StringVarP(&importMode, "mode", "m", string(config.ImportMerge), "导入模式 merge/replace")
+	configImportCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "仅显示差异，不写入")
+}
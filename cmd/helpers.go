@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"dnsfailover/internal/audit"
+	"dnsfailover/internal/storage"
+	"fmt"
+	"os"
+	"os/user"
+)
+
+// mustInitStorage 初始化系统并返回存储实例，失败时退出
+func mustInitStorage() *storage.Storage {
+	if err := InitSystem(); err != nil {
+		exitWithError(fmt.Errorf("系统初始化失败: %w", err))
+	}
+	return storage.GetStorage()
+}
+
+// exitWithError 打印错误并退出
+func exitWithError(err error) {
+	fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+	os.Exit(1)
+}
+
+// cliActor 命令行操作者标识 (cli:<系统用户名>)
+func cliActor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return "cli:" + u.Username
+	}
+	return "cli"
+}
+
+// recordCLIAudit 记录命令行变更操作的审计日志
+func recordCLIAudit(command, action string, before, after interface{}) {
+	audit.Record(&audit.Entry{
+		Actor:    cliActor(),
+		Source:   audit.SourceCLI,
+		Method:   "CLI",
+		Endpoint: "dnsfailover " + command,
+		Action:   action,
+		Before:   before,
+		After:    after,
+	})
+}
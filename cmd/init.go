@@ -53,15 +53,20 @@ func InitSystem() error {
 			return
 		}
 
-		// 从数据库加载配置
-		cfg, err := config.LoadFromDB(baseCfg.DBPath)
-		if err != nil {
-			initError = fmt.Errorf("从数据库加载配置失败: %w", err)
-			return
+		// 配置文件模式下配置完全来自文件，不读取也不写入数据库配置
+		cfg := &config.Config{}
+		if configFile == "" {
+			cfg, err = config.LoadFromDB(baseCfg.DBPath)
+			if err != nil {
+				initError = fmt.Errorf("从数据库加载配置失败: %w", err)
+				return
+			}
 		}
 
 		// 合并日志配置
 		cfg.Log = baseCfg.Log
+		cfg.Dispatch = baseCfg.Dispatch
+		cfg.Probe = baseCfg.Probe
 		cfg.DBPath = baseCfg.DBPath
 
 		// 环境变量中的 Webhook 可覆盖数据库配置
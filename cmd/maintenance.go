@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"dnsfailover/internal/config"
+	"dnsfailover/internal/maintenance"
+	"dnsfailover/internal/storage"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var (
+	maintTargets  []string
+	maintTags     []string
+	maintStart    string
+	maintEnd      string
+	maintCron     string
+	maintDuration int
+	maintComment  string
+
+	maintenanceCmd = &cobra.Command{
+		Use:   "maintenance",
+		Short: "维护窗口管理命令",
+		Long:  "管理计划维护窗口。窗口内探针照常检测，但不发送告警、不升级事件、不触发定时任务的 Webhook。运行中的监控服务会在数秒内生效",
+	}
+
+	maintenanceAddCmd = &cobra.Command{
+		Use:   "add <name>",
+		Short: "创建维护窗口",
+		Example: `  # 一次性窗口
+  dnsfailover maintenance add db-upgrade --target db.example.com:3306 --start "2026-01-10 22:00" --end "2026-01-11 02:00"
+  # 每周日凌晨 2 点起 120 分钟
+  dnsfailover maintenance add weekly --tag prod --cron "0 2 * * 0" --duration 120`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+
+			now := time.Now().Format("2006-01-02 15:04:05")
+			mw := &storage.MaintenanceWindow{
+				ID:        uuid.New().String(),
+				Name:      args[0],
+				Targets:   maintTargets,
+				Tags:      maintTags,
+				StartAt:   maintStart,
+				EndAt:     maintEnd,
+				Cron:      maintCron,
+				Duration:  maintDuration,
+				Comment:   maintComment,
+				CreatedBy: cliActor(),
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if err := config.NormalizeMaintenanceWindow(mw); err != nil {
+				exitWithError(err)
+			}
+			if err := store.SaveMaintenanceWindow(mw); err != nil {
+				exitWithError(err)
+			}
+			recordCLIAudit("maintenance add", "创建维护窗口", nil, mw)
+
+			fmt.Printf("维护窗口已创建: %s\n", mw.Name)
+			fmt.Printf("ID: %s\n", mw.ID)
+		},
+	}
+
+	maintenanceListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "列出所有维护窗口",
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+
+			windows, err := store.GetAllMaintenanceWindows()
+			if err != nil {
+				exitWithError(err)
+			}
+			if len(windows) == 0 {
+				fmt.Println("暂无维护窗口")
+				return
+			}
+
+			now := time.Now()
+			for _, mw := range windows {
+				status := "  "
+				if maintenance.IsActive(mw, now) {
+					status = "● "
+				}
+				when := fmt.Sprintf("%s ~ %s", mw.StartAt, mw.EndAt)
+				if mw.Cron != "" {
+					when = fmt.Sprintf("cron %q 持续 %d 分钟", mw.Cron, mw.Duration)
+				}
+				scope := append(append([]string{}, mw.Targets...), prefixed("tag:", mw.Tags)...)
+				fmt.Printf("%s%-36s %-20s %s  [%s]\n", status, mw.ID, mw.Name, when, strings.Join(scope, ", "))
+			}
+		},
+	}
+
+	maintenanceDeleteCmd = &cobra.Command{
+		Use:   "delete <id>",
+		Short: "删除维护窗口",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+
+			before, _ := store.GetMaintenanceWindow(args[0])
+			if err := store.DeleteMaintenanceWindow(args[0]); err != nil {
+				exitWithError(err)
+			}
+			recordCLIAudit("maintenance delete", "删除维护窗口", before, nil)
+			fmt.Printf("维护窗口已删除: %s\n", args[0])
+		},
+	}
+)
+
+// prefixed 为每一项加上前缀
+func prefixed(prefix string, list []string) []string {
+	result := make([]string, len(list))
+	for i, v := range list {
+		result[i] = prefix + v
+	}
+	return result
+}
+
+func init() {
+	rootCmd.AddCommand(maintenanceCmd)
+	maintenanceCmd.AddCommand(maintenanceAddCmd)
+	maintenanceCmd.AddCommand(maintenanceListCmd)
+	maintenanceCmd.AddCommand(maintenanceDeleteCmd)
+
+	maintenanceAddCmd.Flags().StringSliceVarP(&maintTargets, "target", "t", nil, "生效的目标（可重复或逗号分隔）")
+	maintenanceAddCmd.Flags().StringSliceVar(&maintTags, "tag", nil, "生效的目标标签（可重复或逗号分隔）")
+	maintenanceAddCmd.Flags().StringVar(&maintStart, "start", "", "一次性窗口开始时间，如 \"2026-01-10 22:00\"")
+	maintenanceAddCmd.Flags().StringVar(&maintEnd, "end", "", "一次性窗口结束时间")
+	maintenanceAddCmd.Flags().StringVar(&maintCron, "cron", "", "周期窗口开始时间（标准 5 段 Cron 表达式）")
+	maintenanceAddCmd.Flags().IntVarP(&maintDuration, "duration", "d", 0, "周期窗口持续分钟数")
+	maintenanceAddCmd.Flags().StringVarP(&maintComment, "comment", "c", "", "备注")
+}
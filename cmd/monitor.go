@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"dnsfailover/internal/api"
+	"dnsfailover/internal/audit"
+	"dnsfailover/internal/config"
 	"dnsfailover/internal/logger"
 	"dnsfailover/internal/monitor"
 	"dnsfailover/internal/schedule"
 	"dnsfailover/internal/storage"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
@@ -15,6 +18,13 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const (
+	// scheduleSyncInterval 定时任务同步间隔
+	scheduleSyncInterval = 5 * time.Second
+	// configFileWatchInterval 配置文件变化检查间隔
+	configFileWatchInterval = 5 * time.Second
+)
+
 var (
 	daemonMode      bool
 	apiPort         int
@@ -22,6 +32,11 @@ var (
 	scheduler       *monitor.Scheduler
 	scheduleManager *schedule.Manager
 	apiServer       *api.Server
+	configFile      string
+	configSource    *config.FileSource
+
+	// 最近一次同步的定时任务定义签名
+	scheduleSignature string
 
 	monitorCmd = &cobra.Command{
 		Use:   "monitor",
@@ -40,8 +55,19 @@ var (
 				os.Exit(1)
 			}
 
+			// 配置文件模式：配置完全来自文件，缺省字段使用默认值
+			if configFile != "" {
+				if err := loadConfigFile(); err != nil {
+					logger.Errorf("加载配置文件失败: %v", err)
+					os.Exit(1)
+				}
+			}
+
 			// 创建探针调度器
 			scheduler = monitor.NewScheduler(GetConfig())
+			if configSource != nil {
+				scheduler.SetFileManaged()
+			}
 
 			// 启动监控
 			if err := scheduler.Start(); err != nil {
@@ -71,10 +97,17 @@ var (
 
 			scheduleManager.Start()
 			logger.Infof("定时任务调度器已启动，共 %d 个任务", scheduleManager.GetTaskCount())
+			go watchScheduleTasks()
+			if configSource != nil {
+				go watchConfigFile()
+			}
 
 			// 启动 Web 管理界面
 			if enableWeb {
-				apiServer = api.NewServer(GetConfig(), scheduler, scheduleManager, apiPort)
+				apiServer = api.NewServer(scheduler, scheduleManager, apiPort)
+				if configSource != nil {
+					apiServer.SetConfigSource(configSource)
+				}
 				if err := apiServer.Start(); err != nil {
 					logger.Warnf("启动 Web 管理界面失败: %v", err)
 				}
@@ -113,31 +146,34 @@ var (
 	}
 )
 
-// loadScheduleTasksFromDB 从数据库加载定时任务
+// loadScheduleTasksFromDB 从数据库加载定时任务，并与调度器中的任务对齐
+// 任务定义未变化时跳过，避免重复加载
 func loadScheduleTasksFromDB() error {
 	store := storage.GetStorage()
 	if store == nil {
 		return fmt.Errorf("数据库未初始化")
 	}
 
-	tasks, err := store.GetAllScheduleTasks()
+	stored, err := store.GetAllScheduleTasks()
 	if err != nil {
 		return err
 	}
 
-	for _, st := range tasks {
+	tasks := make([]*schedule.Task, 0, len(stored))
+	for _, st := range stored {
 		task := &schedule.Task{
-			ID:          st.ID,
-			Name:        st.Name,
-			Enabled:     st.Enabled,
-			Cron:        st.Cron,
-			CheckType:   schedule.CheckType(st.CheckType),
-			Target:      st.Target,
-			Port:        st.Port,
-			Timeout:     st.Timeout,
-			WebhookURL:  st.WebhookURL,
-			WebhookData: st.WebhookData,
-			LastResult:  st.LastResult,
+			ID:            st.ID,
+			Name:          st.Name,
+			Enabled:       st.Enabled,
+			Cron:          st.Cron,
+			CheckType:     schedule.CheckType(st.CheckType),
+			Target:        st.Target,
+			Port:          st.Port,
+			Timeout:       st.Timeout,
+			WebhookURL:    st.WebhookURL,
+			WebhookData:   st.WebhookData,
+			WebhookSecret: st.WebhookSecret,
+			LastResult:    st.LastResult,
 		}
 
 		if st.CreatedAt != "" {
@@ -151,14 +187,102 @@ func loadScheduleTasksFromDB() error {
 			task.LastRunAt = &t
 		}
 
-		if err := scheduleManager.AddTask(task); err != nil {
-			logger.Warnf("加载任务失败 [%s]: %v", st.Name, err)
+		tasks = append(tasks, task)
+	}
+
+	// 仅比较任务定义，执行状态的更新不触发重新加载
+	signature, _ := json.Marshal(tasksDefinition(tasks))
+	if string(signature) == scheduleSignature {
+		return nil
+	}
+	scheduleSignature = string(signature)
+
+	scheduleManager.SyncTasks(tasks)
+	return nil
+}
+
+// tasksDefinition 提取任务定义用于变更比较
+func tasksDefinition(tasks []*schedule.Task) []schedule.Task {
+	defs := make([]schedule.Task, 0, len(tasks))
+	for _, task := range tasks {
+		def := *task
+		def.CreatedAt, def.UpdatedAt, def.LastRunAt, def.LastResult = time.Time{}, time.Time{}, nil, ""
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// watchScheduleTasks 定期从数据库同步定时任务（命令行导入等方式修改的任务无需重启即可生效）
+func watchScheduleTasks() {
+	ticker := time.NewTicker(scheduleSyncInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := loadScheduleTasksFromDB(); err != nil {
+			logger.Warnf("同步定时任务失败: %v", err)
 		}
 	}
+}
+
+// loadConfigFile 加载配置文件并以文件内容作为当前配置
+func loadConfigFile() error {
+	src, err := config.LoadFileSource(configFile)
+	if err != nil {
+		return err
+	}
+	config.ApplyStorageConfig(GetConfig(), src.Config())
 
+	if _, err := src.SyncSchedules(storage.GetStorage(), configFileActor()); err != nil {
+		return fmt.Errorf("同步定时任务失败: %w", err)
+	}
+
+	configSource = src
+	logger.Infof("已加载配置文件: %s", configFile)
 	return nil
 }
 
+// watchConfigFile 定期检查配置文件，有变化时热加载
+// 新内容校验失败时保留当前配置并记录错误
+func watchConfigFile() {
+	ticker := time.NewTicker(configFileWatchInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		changed, err := configSource.Reload()
+		if err != nil {
+			logger.Errorf("配置文件重新加载失败，继续使用当前配置: %v", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		before := scheduler.CurrentConfig()
+		scheduler.ApplyConfig(configSource.Config(), 0)
+
+		tasks, err := configSource.SyncSchedules(storage.GetStorage(), configFileActor())
+		if err != nil {
+			logger.Errorf("同步定时任务失败: %v", err)
+		}
+
+		logger.Infof("配置文件已重新加载: %s (%d 个定时任务变更)", configFile, len(tasks))
+		audit.Record(&audit.Entry{
+			Actor:    configFileActor(),
+			Source:   audit.SourceFile,
+			Method:   "RELOAD",
+			Endpoint: configFile,
+			Action:   "重新加载配置文件",
+			Before:   config.RedactFullConfig(before),
+			After:    config.RedactFullConfig(scheduler.CurrentConfig()),
+		})
+	}
+}
+
+// configFileActor 配置文件操作者标识
+func configFileActor() string {
+	return "file:" + configFile
+}
+
 func init() {
 	rootCmd.AddCommand(monitorCmd)
 	monitorCmd.AddCommand(monitorStartCmd)
@@ -167,4 +291,5 @@ func init() {
 	monitorStartCmd.Flags().BoolVarP(&daemonMode, "daemon", "d", false, "以后台模式运行")
 	monitorStartCmd.Flags().BoolVarP(&enableWeb, "web", "w", true, "启用 Web 管理界面")
 	monitorStartCmd.Flags().IntVarP(&apiPort, "port", "p", 8080, "Web 管理界面端口")
+	monitorStartCmd.Flags().StringVarP(&configFile, "config", "c", "", "配置文件路径 (YAML/JSON，格式同 config export)，配置完全来自文件 (缺省字段使用默认值)，只读并支持热加载")
 }
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"dnsfailover/internal/silence"
+	"dnsfailover/internal/storage"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	silenceDuration time.Duration
+	silenceReason   string
+
+	silenceCmd = &cobra.Command{
+		Use:   "silence",
+		Short: "目标静音命令",
+		Long:  "临时静音检测目标：静音期间探针照常检测，但不发送该目标的告警通知，到期自动解除。运行中的监控服务会在数秒内生效",
+	}
+
+	silenceAddCmd = &cobra.Command{
+		Use:     "add <target>",
+		Short:   "静音目标（已静音时重新设置到期时间）",
+		Example: `  dnsfailover silence add example.com:443 --duration 2h --reason "证书更换"`,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+			mustBeConfiguredTarget(store, args[0])
+
+			ts, err := silence.New(args[0], silenceDuration, silenceReason, cliActor())
+			if err != nil {
+				exitWithError(err)
+			}
+			before, _ := store.GetTargetSilence(ts.Target)
+			if err := store.SaveTargetSilence(ts); err != nil {
+				exitWithError(err)
+			}
+			recordCLIAudit("silence add", "静音目标", before, ts)
+			fmt.Printf("目标已静音: %s，至 %s\n", ts.Target, ts.ExpiresAt)
+		},
+	}
+
+	silenceListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "列出静音中的目标",
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+
+			silences, err := store.GetActiveTargetSilences(time.Now().Format(silence.TimeLayout))
+			if err != nil {
+				exitWithError(err)
+			}
+			if len(silences) == 0 {
+				fmt.Println("暂无静音中的目标")
+				return
+			}
+			for _, ts := range silences {
+				fmt.Printf("%-40s 至 %s  %-20s %s\n", ts.Target, ts.ExpiresAt, ts.CreatedBy, ts.Reason)
+			}
+		},
+	}
+
+	silenceDeleteCmd = &cobra.Command{
+		Use:   "delete <target>",
+		Short: "提前解除目标静音",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+
+			before, _ := store.GetTargetSilence(args[0])
+			if err := store.DeleteTargetSilence(args[0]); err != nil {
+				exitWithError(err)
+			}
+			recordCLIAudit("silence delete", "解除目标静音", before, nil)
+			fmt.Printf("已解除静音: %s\n", args[0])
+		},
+	}
+)
+
+// mustBeConfiguredTarget 校验目标在当前配置中存在，否则退出
+func mustBeConfiguredTarget(store *storage.Storage, target string) {
+	cfg, err := store.LoadConfig()
+	if err != nil {
+		exitWithError(err)
+	}
+	if cfg == nil {
+		exitWithError(fmt.Errorf("尚未保存任何配置"))
+	}
+	for _, probe := range []storage.ProbeConfig{cfg.Ping, cfg.Tcp, cfg.Http} {
+		for _, domain := range probe.Domains {
+			if domain == target {
+				return
+			}
+		}
+	}
+	exitWithError(fmt.Errorf("检测目标不存在: %s", target))
+}
+
+func init() {
+	rootCmd.AddCommand(silenceCmd)
+	silenceCmd.AddCommand(silenceAddCmd)
+	silenceCmd.AddCommand(silenceListCmd)
+	silenceCmd.AddCommand(silenceDeleteCmd)
+
+	silenceAddCmd.Flags().DurationVarP(&silenceDuration, "duration", "d", time.Hour, "静音时长，如 30m、2h")
+	silenceAddCmd.Flags().StringVarP(&silenceReason, "reason", "r", "", "静音原因")
+}
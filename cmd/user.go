@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"dnsfailover/internal/auth"
+	"dnsfailover/internal/storage"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var (
+	userPassword string
+	userRole     string
+	tokenRole    string
+
+	userCmd = &cobra.Command{
+		Use:   "user",
+		Short: "用户管理命令",
+		Long:  "管理 Web 管理界面与 API 的登录用户。创建第一个用户或 Token 后即启用认证",
+	}
+
+	userAddCmd = &cobra.Command{
+		Use:   "add <username>",
+		Short: "创建或更新用户",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+
+			role, err := auth.ParseRole(userRole)
+			if err != nil {
+				exitWithError(err)
+			}
+			if userPassword == "" {
+				exitWithError(fmt.Errorf("请通过 --password 指定密码"))
+			}
+
+			hash, err := auth.HashPassword(userPassword)
+			if err != nil {
+				exitWithError(err)
+			}
+			before, _ := store.GetUser(args[0])
+			user := &storage.User{Username: args[0], PasswordHash: hash, Role: string(role)}
+			if err := store.SaveUser(user); err != nil {
+				exitWithError(err)
+			}
+			recordCLIAudit("user add", "保存用户", before, user)
+			fmt.Printf("用户已保存: %s (%s)\n", args[0], role)
+		},
+	}
+
+	userListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "列出所有用户",
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+
+			users, err := store.GetAllUsers()
+			if err != nil {
+				exitWithError(err)
+			}
+			if len(users) == 0 {
+				fmt.Println("暂无用户")
+				return
+			}
+			for _, u := range users {
+				fmt.Printf("%-20s %-10s %s\n", u.Username, u.Role, u.CreatedAt)
+			}
+		},
+	}
+
+	userDeleteCmd = &cobra.Command{
+		Use:   "delete <username>",
+		Short: "删除用户",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+
+			before, _ := store.GetUser(args[0])
+			if err := store.DeleteUser(args[0]); err != nil {
+				exitWithError(err)
+			}
+			recordCLIAudit("user delete", "删除用户", before, nil)
+			fmt.Printf("用户已删除: %s\n", args[0])
+		},
+	}
+
+	tokenCmd = &cobra.Command{
+		Use:   "token",
+		Short: "API Token 管理命令",
+		Long:  "管理用于脚本与自动化调用的 API Token，请求时携带 Authorization: Bearer <token>",
+	}
+
+	tokenCreateCmd = &cobra.Command{
+		Use:   "create <name>",
+		Short: "创建 API Token",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+
+			role, err := auth.ParseRole(tokenRole)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			plain, err := auth.GenerateToken()
+			if err != nil {
+				exitWithError(err)
+			}
+
+			token := &storage.APIToken{
+				ID:        uuid.New().String(),
+				Name:      args[0],
+				TokenHash: auth.HashToken(plain),
+				Role:      string(role),
+				CreatedBy: cliActor(),
+			}
+			if err := store.SaveAPIToken(token); err != nil {
+				exitWithError(err)
+			}
+			recordCLIAudit("token create", "创建 Token", nil, token)
+
+			fmt.Printf("Token 已创建: %s (%s)\n", token.Name, token.Role)
+			fmt.Printf("ID:    %s\n", token.ID)
+			fmt.Printf("Token: %s\n", plain)
+			fmt.Println("请妥善保存 Token，之后将无法再次查看")
+		},
+	}
+
+	tokenListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "列出所有 API Token",
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+
+			tokens, err := store.GetAllAPITokens()
+			if err != nil {
+				exitWithError(err)
+			}
+			if len(tokens) == 0 {
+				fmt.Println("暂无 Token")
+				return
+			}
+			for _, t := range tokens {
+				lastUsed := "-"
+				if t.LastUsedAt != nil {
+					lastUsed = *t.LastUsedAt
+				}
+				fmt.Printf("%-36s %-20s %-10s 最后使用: %s\n", t.ID, t.Name, t.Role, lastUsed)
+			}
+		},
+	}
+
+	tokenRevokeCmd = &cobra.Command{
+		Use:   "revoke <id>",
+		Short: "吊销 API Token",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := mustInitStorage()
+
+			if err := store.DeleteAPIToken(args[0]); err != nil {
+				exitWithError(err)
+			}
+			recordCLIAudit("token revoke", "吊销 Token", map[string]string{"id": args[0]}, nil)
+			fmt.Printf("Token 已吊销: %s\n", args[0])
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(userCmd)
+	userCmd.AddCommand(userAddCmd)
+	userCmd.AddCommand(userListCmd)
+	userCmd.AddCommand(userDeleteCmd)
+
+	userAddCmd.Flags().StringVarP(&userPassword, "password", "P", "", "登录密码")
+	userAddCmd.Flags().StringVarP(&userRole, "role", "r", string(auth.RoleViewer), "角色 (viewer/operator/admin)")
+
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.AddCommand(tokenCreateCmd)
+	tokenCmd.AddCommand(tokenListCmd)
+	tokenCmd.AddCommand(tokenRevokeCmd)
+
+	tokenCreateCmd.Flags().StringVarP(&tokenRole, "role", "r", string(auth.RoleViewer), "角色 (viewer/operator/admin)")
+}
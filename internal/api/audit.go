@@ -0,0 +1,73 @@
+package api
+
+import (
+	"dnsfailover/internal/audit"
+	"dnsfailover/internal/auth"
+	"dnsfailover/internal/storage"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// actorName 获取当前请求的操作者名称
+func actorName(r *http.Request) string {
+	if principal := auth.FromContext(r.Context()); principal != nil {
+		return principal.Name
+	}
+	return "unknown"
+}
+
+// recordAudit 记录一次变更操作的审计日志
+func (s *Server) recordAudit(r *http.Request, action string, before, after interface{}) {
+	audit.Record(&audit.Entry{
+		Actor:    actorName(r),
+		Source:   audit.SourceAPI,
+		SourceIP: clientIP(r),
+		Method:   r.Method,
+		Endpoint: r.URL.Path,
+		Action:   action,
+		Before:   before,
+		After:    after,
+	})
+}
+
+// clientIP 获取请求来源 IP
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// handleGetAudit 查询审计日志
+// 支持参数: actor, endpoint, action, source, since, until, limit
+func (s *Server) handleGetAudit(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := storage.AuditFilter{
+		Actor:    q.Get("actor"),
+		Endpoint: q.Get("endpoint"),
+		Action:   q.Get("action"),
+		Source:   q.Get("source"),
+		Since:    q.Get("since"),
+		Until:    q.Get("until"),
+	}
+	if limit := q.Get("limit"); limit != "" {
+		filter.Limit, _ = strconv.Atoi(limit)
+	}
+
+	logs, err := store.QueryAuditLogs(filter)
+	if err != nil {
+		respondError(w, fmt.Sprintf("查询审计日志失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	respondSuccess(w, "获取成功", logs)
+}
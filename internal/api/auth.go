@@ -0,0 +1,337 @@
+package api
+
+import (
+	"dnsfailover/internal/auth"
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/storage"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// anonymousAdmin 未配置任何用户/Token 时的访问主体（兼容旧部署）
+var anonymousAdmin = &auth.Principal{Name: "anonymous", Kind: "anonymous", Role: auth.RoleAdmin}
+
+// authMiddleware 认证中间件：解析 Basic 认证或 Bearer Token，写入访问主体
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := s.authenticate(r)
+		if err != nil {
+			logger.Warnf("[API] 认证失败 (%s %s, 来源 %s): %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+			respondUnauthorized(w, err.Error())
+			return
+		}
+
+		if principal != nil {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate 根据请求头识别访问主体，未携带凭据时返回 nil
+func (s *Server) authenticate(r *http.Request) (*auth.Principal, error) {
+	store := storage.GetStorage()
+	if store == nil {
+		return anonymousAdmin, nil
+	}
+
+	// 未创建任何用户或 Token 时不启用认证
+	enabled, err := store.HasCredentials()
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return anonymousAdmin, nil
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(header, "Bearer ") {
+		plain := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+		token, err := store.GetAPITokenByHash(auth.HashToken(plain))
+		if err != nil {
+			return nil, err
+		}
+		if token == nil {
+			return nil, fmt.Errorf("无效的 API Token")
+		}
+		role, err := auth.ParseRole(token.Role)
+		if err != nil {
+			return nil, err
+		}
+		store.TouchAPIToken(token.ID)
+		return &auth.Principal{Name: token.Name, Kind: "token", Role: role}, nil
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, fmt.Errorf("不支持的认证方式")
+	}
+	user, err := store.GetUser(username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !auth.CheckPassword(password, user.PasswordHash) {
+		return nil, fmt.Errorf("用户名或密码错误")
+	}
+	role, err := auth.ParseRole(user.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Principal{Name: user.Username, Kind: "user", Role: role}, nil
+}
+
+// require 包装处理函数，要求访问主体至少具备指定角色
+func (s *Server) require(role auth.Role, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := auth.FromContext(r.Context())
+		if principal == nil {
+			respondUnauthorized(w, "需要登录")
+			return
+		}
+		if !principal.Role.Allows(role) {
+			respondError(w, fmt.Sprintf("权限不足: 需要 %s 角色 (当前: %s)", role, principal.Role), http.StatusForbidden)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// respondUnauthorized 未认证响应（触发浏览器 Basic 认证弹窗）
+func respondUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="dnsfailover", charset="UTF-8"`)
+	respondError(w, message, http.StatusUnauthorized)
+}
+
+// handleGetMe 获取当前访问主体
+func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) {
+	principal := auth.FromContext(r.Context())
+	respondSuccess(w, "获取成功", map[string]interface{}{
+		"name":         principal.Name,
+		"kind":         principal.Kind,
+		"role":         principal.Role,
+		"auth_enabled": principal.Kind != "anonymous",
+	})
+}
+
+// ========== 用户与 Token 管理 API ==========
+
+// handleGetUsers 获取用户列表
+func (s *Server) handleGetUsers(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	users, err := store.GetAllUsers()
+	if err != nil {
+		respondError(w, fmt.Sprintf("获取用户列表失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	respondSuccess(w, "获取成功", users)
+}
+
+// handleSaveUser 创建或更新用户
+func (s *Server) handleSaveUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Role     string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "无效的 JSON 格式", http.StatusBadRequest)
+		return
+	}
+
+	if req.Username == "" || req.Password == "" {
+		respondError(w, "用户名和密码不能为空", http.StatusBadRequest)
+		return
+	}
+	role, err := auth.ParseRole(req.Role)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	before, _ := storage.GetStorage().GetUser(req.Username)
+	added, adminDelta := 1, adminCount(role)
+	if before != nil {
+		added = 0
+		adminDelta -= adminCount(auth.Role(before.Role))
+	}
+	if !s.checkAdminRemains(w, added, adminDelta) {
+		return
+	}
+
+	user := &storage.User{Username: req.Username, PasswordHash: hash, Role: string(role)}
+	if err := storage.GetStorage().SaveUser(user); err != nil {
+		respondError(w, fmt.Sprintf("保存用户失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Infof("[API] 保存用户: %s (%s)", user.Username, user.Role)
+	s.recordAudit(r, "保存用户", before, user)
+	respondSuccess(w, "保存成功", user)
+}
+
+// handleDeleteUser 删除用户
+func (s *Server) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	username, err := url.PathUnescape(mux.Vars(r)["username"])
+	if err != nil {
+		respondError(w, "无效的用户名", http.StatusBadRequest)
+		return
+	}
+
+	before, _ := storage.GetStorage().GetUser(username)
+	if before != nil && !s.checkAdminRemains(w, -1, -adminCount(auth.Role(before.Role))) {
+		return
+	}
+	if err := storage.GetStorage().DeleteUser(username); err != nil {
+		respondError(w, fmt.Sprintf("删除失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Infof("[API] 删除用户: %s", username)
+	s.recordAudit(r, "删除用户", before, nil)
+	respondSuccess(w, "删除成功", nil)
+}
+
+// handleGetTokens 获取 Token 列表
+func (s *Server) handleGetTokens(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	tokens, err := store.GetAllAPITokens()
+	if err != nil {
+		respondError(w, fmt.Sprintf("获取 Token 列表失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	respondSuccess(w, "获取成功", tokens)
+}
+
+// handleCreateToken 创建 Token，明文仅在响应中返回一次
+func (s *Server) handleCreateToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "无效的 JSON 格式", http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" {
+		respondError(w, "Token 名称不能为空", http.StatusBadRequest)
+		return
+	}
+	role, err := auth.ParseRole(req.Role)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !s.checkAdminRemains(w, 1, adminCount(role)) {
+		return
+	}
+
+	plain, err := auth.GenerateToken()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	token := &storage.APIToken{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		TokenHash: auth.HashToken(plain),
+		Role:      string(role),
+		CreatedBy: auth.FromContext(r.Context()).Name,
+	}
+	if err := storage.GetStorage().SaveAPIToken(token); err != nil {
+		respondError(w, fmt.Sprintf("保存 Token 失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Infof("[API] 创建 Token: %s (%s)", token.Name, token.Role)
+	s.recordAudit(r, "创建 Token", nil, token)
+	respondSuccess(w, "创建成功，请妥善保存 Token，之后将无法再次查看", map[string]interface{}{
+		"id":    token.ID,
+		"name":  token.Name,
+		"role":  token.Role,
+		"token": plain,
+	})
+}
+
+// handleDeleteToken 吊销 Token
+func (s *Server) handleDeleteToken(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	tokens, err := storage.GetStorage().GetAllAPITokens()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, token := range tokens {
+		if token.ID == id && !s.checkAdminRemains(w, -1, -adminCount(auth.Role(token.Role))) {
+			return
+		}
+	}
+
+	if err := storage.GetStorage().DeleteAPIToken(id); err != nil {
+		respondError(w, fmt.Sprintf("吊销失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Infof("[API] 吊销 Token: %s", id)
+	s.recordAudit(r, "吊销 Token", map[string]string{"id": id}, nil)
+	respondSuccess(w, "吊销成功", nil)
+}
+
+// checkAdminRemains 检查变更后是否仍有管理员凭据（added 为凭据数变化，adminDelta 为管理员数变化）
+// 只要存在凭据就会启用认证，没有管理员时所有人都无法再管理用户与配置，此时返回 400 并拒绝变更
+func (s *Server) checkAdminRemains(w http.ResponseWriter, added, adminDelta int) bool {
+	total, admins, err := storage.GetStorage().CountCredentials()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if total+added > 0 && admins+adminDelta <= 0 {
+		respondError(w, "操作后将没有 admin 角色的用户或 Token，启用认证后无人能够管理，请先创建管理员", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// adminCount 管理员角色计 1，其他角色计 0
+func adminCount(role auth.Role) int {
+	if role == auth.RoleAdmin {
+		return 1
+	}
+	return 0
+}
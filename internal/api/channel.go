@@ -0,0 +1,364 @@
+package api
+
+import (
+	"dnsfailover/internal/config"
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/storage"
+	"dnsfailover/internal/webhook"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// ========== 通知通道与路由规则 API ==========
+
+// handleGetChannels 获取所有通知通道
+func (s *Server) handleGetChannels(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	channels, err := store.GetAllChannels()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondSuccess(w, "获取成功", redactChannels(channels))
+}
+
+// handleSaveChannel 创建或更新通知通道（路径中带 id 时为更新）
+func (s *Server) handleSaveChannel(w http.ResponseWriter, r *http.Request) {
+	var req storage.NotifyChannel
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "无效的 JSON 格式", http.StatusBadRequest)
+		return
+	}
+
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	var before *storage.NotifyChannel
+	if id := mux.Vars(r)["id"]; id != "" {
+		existing, err := store.GetChannel(id)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if existing == nil {
+			respondError(w, "通知通道不存在", http.StatusNotFound)
+			return
+		}
+		before = existing
+		req.ID = existing.ID
+		req.CreatedAt = existing.CreatedAt
+		// 接口返回的密钥与密码已隐藏，提交为空或占位符时保留原值
+		req.Secret = config.KeepSecret(req.Secret, existing.Secret)
+		keepEmailPassword(req.Email, existing.Email)
+	} else {
+		req.ID = uuid.New().String()
+		req.CreatedAt = now
+	}
+	req.UpdatedAt = now
+
+	if err := config.NormalizeChannel(&req); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := webhook.ValidateTemplate(req.Template); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 名称唯一
+	channels, err := store.GetAllChannels()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, ch := range channels {
+		if ch.Name == req.Name && ch.ID != req.ID {
+			respondError(w, fmt.Sprintf("通道名称已存在: %s", req.Name), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := store.SaveChannel(&req); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if before == nil {
+		logger.Infof("[API] 创建通知通道: %s (%s)", req.Name, req.URL)
+		s.recordAudit(r, "创建通知通道", nil, redactChannel(&req))
+	} else {
+		logger.Infof("[API] 更新通知通道: %s", req.Name)
+		s.recordAudit(r, "更新通知通道", redactChannel(before), redactChannel(&req))
+	}
+	respondSuccess(w, "保存成功", redactChannel(&req))
+}
+
+// handleDeleteChannel 删除通知通道（仍被路由规则或升级策略引用时拒绝删除）
+func (s *Server) handleDeleteChannel(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	existing, err := store.GetChannel(id)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing == nil {
+		respondError(w, "通知通道不存在", http.StatusNotFound)
+		return
+	}
+
+	rules, err := store.GetAllRouteRules()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, rule := range rules {
+		for _, channelID := range rule.Channels {
+			if channelID == id {
+				respondError(w, fmt.Sprintf("通道仍被路由规则「%s」使用，请先修改规则", rule.Name), http.StatusConflict)
+				return
+			}
+		}
+	}
+
+	policies, err := store.GetAllEscalationPolicies()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, policy := range policies {
+		for _, step := range policy.Steps {
+			for _, channelID := range step.Channels {
+				if channelID == id {
+					respondError(w, fmt.Sprintf("通道仍被升级策略「%s」使用，请先修改策略", policy.Name), http.StatusConflict)
+					return
+				}
+			}
+		}
+	}
+
+	if err := store.DeleteChannel(id); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Infof("[API] 删除通知通道: %s", existing.Name)
+	s.recordAudit(r, "删除通知通道", redactChannel(existing), nil)
+	respondSuccess(w, "删除成功", nil)
+}
+
+// handleTestChannel 向通知通道发送测试消息
+func (s *Server) handleTestChannel(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	ch, err := store.GetChannel(mux.Vars(r)["id"])
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ch == nil {
+		respondError(w, "通知通道不存在", http.StatusNotFound)
+		return
+	}
+
+	dest := &webhook.Destination{
+		Channel:  ch.Name,
+		Type:     ch.Type,
+		URL:      ch.URL,
+		Secret:   ch.Secret,
+		Template: ch.Template,
+		Email:    ch.Email,
+		Method:   ch.Method,
+		Headers:  ch.Headers,
+		Timeout:  ch.Timeout,
+	}
+	if err := webhook.SendTest(dest); err != nil {
+		respondError(w, "发送失败: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	s.recordAudit(r, "测试通知通道", nil, map[string]interface{}{"channel": ch.Name, "url": ch.URL})
+	respondSuccess(w, "发送成功", nil)
+}
+
+// handlePreviewTemplate 使用示例告警渲染消息模板
+// 模板为空时按通道类型返回内置格式；alert_type 可选 down/recovery，用于预览不同告警
+func (s *Server) handlePreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Type      string `json:"type"`
+		Template  string `json:"template"`
+		AlertType string `json:"alert_type"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "无效的 JSON 格式", http.StatusBadRequest)
+		return
+	}
+
+	alert := webhook.SampleAlert()
+	if req.AlertType == string(webhook.AlertTypeRecovery) {
+		alert.Type = webhook.AlertTypeRecovery
+		alert.Error = ""
+	}
+
+	body, err := webhook.Preview(&webhook.Destination{Type: req.Type, Template: req.Template}, alert)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondSuccess(w, "渲染成功", map[string]interface{}{
+		"alert": alert,
+		"body":  string(body),
+	})
+}
+
+// handleGetRoutes 获取所有路由规则
+func (s *Server) handleGetRoutes(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	rules, err := store.GetAllRouteRules()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondSuccess(w, "获取成功", rules)
+}
+
+// handleSaveRoute 创建或更新路由规则（路径中带 id 时为更新）
+func (s *Server) handleSaveRoute(w http.ResponseWriter, r *http.Request) {
+	var req storage.RouteRule
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "无效的 JSON 格式", http.StatusBadRequest)
+		return
+	}
+
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	var before *storage.RouteRule
+	if id := mux.Vars(r)["id"]; id != "" {
+		existing, err := store.GetRouteRule(id)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if existing == nil {
+			respondError(w, "路由规则不存在", http.StatusNotFound)
+			return
+		}
+		before = existing
+		req.ID = existing.ID
+		req.CreatedAt = existing.CreatedAt
+	} else {
+		req.ID = uuid.New().String()
+		req.CreatedAt = now
+	}
+	req.UpdatedAt = now
+
+	if err := config.NormalizeRouteRule(&req); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 引用的通道必须存在
+	for _, channelID := range req.Channels {
+		ch, err := store.GetChannel(channelID)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if ch == nil {
+			respondError(w, fmt.Sprintf("通知通道不存在: %s", channelID), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Escalation != "" {
+		policy, err := store.GetEscalationPolicy(req.Escalation)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if policy == nil {
+			respondError(w, fmt.Sprintf("升级策略不存在: %s", req.Escalation), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := store.SaveRouteRule(&req); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if before == nil {
+		logger.Infof("[API] 创建路由规则: %s", req.Name)
+		s.recordAudit(r, "创建路由规则", nil, &req)
+	} else {
+		logger.Infof("[API] 更新路由规则: %s", req.Name)
+		s.recordAudit(r, "更新路由规则", before, &req)
+	}
+	respondSuccess(w, "保存成功", &req)
+}
+
+// handleDeleteRoute 删除路由规则
+func (s *Server) handleDeleteRoute(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	existing, err := store.GetRouteRule(id)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing == nil {
+		respondError(w, "路由规则不存在", http.StatusNotFound)
+		return
+	}
+
+	if err := store.DeleteRouteRule(id); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Infof("[API] 删除路由规则: %s", existing.Name)
+	s.recordAudit(r, "删除路由规则", existing, nil)
+	respondSuccess(w, "删除成功", nil)
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"dnsfailover/internal/config"
+	"fmt"
+	"net/http"
+)
+
+// SetConfigSource 设置配置文件（GitOps 模式），配置与文件管理的定时任务通过 API 只读
+func (s *Server) SetConfigSource(src *config.FileSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.configSource = src
+}
+
+// handleGetConfigLocks 获取被配置文件锁定的字段
+func (s *Server) handleGetConfigLocks(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	src := s.configSource
+	s.mu.RUnlock()
+
+	locks := map[string]interface{}{
+		"file":      "",
+		"fields":    []string{},
+		"schedules": false,
+	}
+	if src != nil {
+		locks["file"] = src.Path()
+		locks["fields"] = src.LockedFields()
+		locks["schedules"] = src.SchedulesLocked()
+	}
+
+	respondSuccess(w, "获取成功", locks)
+}
+
+// unlessConfigFileManaged 配置由配置文件管理时拒绝通过 API 修改配置
+func (s *Server) unlessConfigFileManaged(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		src := s.configSource
+		s.mu.RUnlock()
+
+		if src != nil {
+			respondError(w, fmt.Sprintf("配置由配置文件 %s 管理，不能通过 API 修改，请修改文件后自动生效", src.Path()), http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// unlessSchedulesLocked 定时任务由配置文件管理时拒绝修改
+func (s *Server) unlessSchedulesLocked(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		src := s.configSource
+		s.mu.RUnlock()
+
+		if src != nil && src.SchedulesLocked() {
+			respondError(w, fmt.Sprintf("定时任务由配置文件 %s 管理，不能通过 API 修改", src.Path()), http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"dnsfailover/internal/config"
+	"dnsfailover/internal/incident"
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/storage"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// ========== 升级策略与故障事件 API ==========
+
+// handleGetEscalations 获取所有升级策略
+func (s *Server) handleGetEscalations(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	policies, err := store.GetAllEscalationPolicies()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondSuccess(w, "获取成功", policies)
+}
+
+// handleSaveEscalation 创建或更新升级策略（路径中带 id 时为更新）
+func (s *Server) handleSaveEscalation(w http.ResponseWriter, r *http.Request) {
+	var req storage.EscalationPolicy
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "无效的 JSON 格式", http.StatusBadRequest)
+		return
+	}
+
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	var before *storage.EscalationPolicy
+	if id := mux.Vars(r)["id"]; id != "" {
+		existing, err := store.GetEscalationPolicy(id)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if existing == nil {
+			respondError(w, "升级策略不存在", http.StatusNotFound)
+			return
+		}
+		before = existing
+		req.ID = existing.ID
+		req.CreatedAt = existing.CreatedAt
+	} else {
+		req.ID = uuid.New().String()
+		req.CreatedAt = now
+	}
+	req.UpdatedAt = now
+
+	if err := config.NormalizeEscalationPolicy(&req); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 引用的通道必须存在
+	for _, step := range req.Steps {
+		for _, channelID := range step.Channels {
+			ch, err := store.GetChannel(channelID)
+			if err != nil {
+				respondError(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if ch == nil {
+				respondError(w, fmt.Sprintf("通知通道不存在: %s", channelID), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	// 名称唯一
+	policies, err := store.GetAllEscalationPolicies()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, policy := range policies {
+		if policy.Name == req.Name && policy.ID != req.ID {
+			respondError(w, fmt.Sprintf("策略名称已存在: %s", req.Name), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := store.SaveEscalationPolicy(&req); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if before == nil {
+		logger.Infof("[API] 创建升级策略: %s", req.Name)
+		s.recordAudit(r, "创建升级策略", nil, &req)
+	} else {
+		logger.Infof("[API] 更新升级策略: %s", req.Name)
+		s.recordAudit(r, "更新升级策略", before, &req)
+	}
+	respondSuccess(w, "保存成功", &req)
+}
+
+// handleDeleteEscalation 删除升级策略（仍被路由规则引用时拒绝删除）
+func (s *Server) handleDeleteEscalation(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	existing, err := store.GetEscalationPolicy(id)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing == nil {
+		respondError(w, "升级策略不存在", http.StatusNotFound)
+		return
+	}
+
+	rules, err := store.GetAllRouteRules()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, rule := range rules {
+		if rule.Escalation == id {
+			respondError(w, fmt.Sprintf("策略仍被路由规则「%s」使用，请先修改规则", rule.Name), http.StatusConflict)
+			return
+		}
+	}
+
+	if err := store.DeleteEscalationPolicy(id); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Infof("[API] 删除升级策略: %s", existing.Name)
+	s.recordAudit(r, "删除升级策略", existing, nil)
+	respondSuccess(w, "删除成功", nil)
+}
+
+// handleGetIncidents 查询故障事件
+// 支持参数: status (open/acknowledged/resolved，可用逗号分隔多个), limit
+func (s *Server) handleGetIncidents(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	incidents, err := store.QueryIncidents(r.URL.Query().Get("status"), limit)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondSuccess(w, "获取成功", incidents)
+}
+
+// handleGetIncident 获取单个故障事件及其时间线
+func (s *Server) handleGetIncident(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	inc, err := store.GetIncident(id)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if inc == nil {
+		respondError(w, "故障事件不存在", http.StatusNotFound)
+		return
+	}
+
+	inc.Events, err = store.GetIncidentEvents(id)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondSuccess(w, "获取成功", inc)
+}
+
+// handleAckIncident 确认故障事件，停止后续升级
+// 请求体可选: {"comment": "处理中"}
+func (s *Server) handleAckIncident(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Comment string `json:"comment"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, "无效的 JSON 格式", http.StatusBadRequest)
+			return
+		}
+	}
+
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	before, err := store.GetIncident(id)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if before == nil {
+		respondError(w, "故障事件不存在", http.StatusNotFound)
+		return
+	}
+
+	inc, err := incident.Acknowledge(id, actorName(r), req.Comment)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	logger.Infof("[API] 确认故障事件 #%d: %s [%s]", inc.ID, inc.Target, inc.ProbeType)
+	s.recordAudit(r, fmt.Sprintf("确认故障事件 #%d", inc.ID), map[string]interface{}{"status": before.Status},
+		map[string]interface{}{"status": inc.Status, "comment": req.Comment})
+	respondSuccess(w, "已确认", inc)
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"dnsfailover/internal/config"
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/maintenance"
+	"dnsfailover/internal/storage"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// ========== 维护窗口 API ==========
+
+// maintenanceView 维护窗口及其当前状态
+type maintenanceView struct {
+	*storage.MaintenanceWindow
+	Active    bool   `json:"active"`     // 当前是否处于窗口中
+	NextStart string `json:"next_start"` // 下一次开始时间，为空表示不会再开始
+}
+
+// handleGetMaintenance 获取所有维护窗口及其当前状态
+func (s *Server) handleGetMaintenance(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	windows, err := store.GetAllMaintenanceWindows()
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	views := make([]maintenanceView, 0, len(windows))
+	for _, mw := range windows {
+		view := maintenanceView{MaintenanceWindow: mw, Active: maintenance.IsActive(mw, now)}
+		if next := maintenance.NextStart(mw, now); !next.IsZero() {
+			view.NextStart = next.Format(maintenance.TimeLayout)
+		}
+		views = append(views, view)
+	}
+
+	respondSuccess(w, "获取成功", views)
+}
+
+// handleSaveMaintenance 创建或更新维护窗口（路径中带 id 时为更新）
+func (s *Server) handleSaveMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req storage.MaintenanceWindow
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "无效的 JSON 格式", http.StatusBadRequest)
+		return
+	}
+
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	var before *storage.MaintenanceWindow
+	if id := mux.Vars(r)["id"]; id != "" {
+		existing, err := store.GetMaintenanceWindow(id)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if existing == nil {
+			respondError(w, "维护窗口不存在", http.StatusNotFound)
+			return
+		}
+		before = existing
+		req.ID = existing.ID
+		req.CreatedBy = existing.CreatedBy
+		req.CreatedAt = existing.CreatedAt
+	} else {
+		req.ID = uuid.New().String()
+		req.CreatedBy = actorName(r)
+		req.CreatedAt = now
+	}
+	req.UpdatedAt = now
+
+	if err := config.NormalizeMaintenanceWindow(&req); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := store.SaveMaintenanceWindow(&req); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	maintenance.Invalidate()
+
+	if before == nil {
+		logger.Infof("[API] 创建维护窗口: %s", req.Name)
+		s.recordAudit(r, "创建维护窗口", nil, &req)
+	} else {
+		logger.Infof("[API] 更新维护窗口: %s", req.Name)
+		s.recordAudit(r, "更新维护窗口", before, &req)
+	}
+	respondSuccess(w, "保存成功", &req)
+}
+
+// handleDeleteMaintenance 删除维护窗口
+func (s *Server) handleDeleteMaintenance(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	existing, err := store.GetMaintenanceWindow(id)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing == nil {
+		respondError(w, "维护窗口不存在", http.StatusNotFound)
+		return
+	}
+
+	if err := store.DeleteMaintenanceWindow(id); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	maintenance.Invalidate()
+
+	logger.Infof("[API] 删除维护窗口: %s", existing.Name)
+	s.recordAudit(r, "删除维护窗口", existing, nil)
+	respondSuccess(w, "删除成功", nil)
+}
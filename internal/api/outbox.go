@@ -0,0 +1,79 @@
+package api
+
+import (
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/storage"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// handleGetDeliveries 查询 Webhook 投递记录（地址中的 Token 与敏感请求头已隐藏）
+// 支持参数: status (pending/delivered/failed), limit
+func (s *Server) handleGetDeliveries(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	entries, err := store.QueryOutbox(r.URL.Query().Get("status"), limit)
+	if err != nil {
+		respondError(w, fmt.Sprintf("查询投递记录失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	respondSuccess(w, "获取成功", redactDeliveries(entries))
+}
+
+// handleGetWebhookStats 获取告警分发队列与投递记录统计
+func (s *Server) handleGetWebhookStats(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{}
+	if s.scheduler != nil {
+		data["dispatcher"] = s.scheduler.WebhookStats()
+	}
+
+	if store := storage.GetStorage(); store != nil {
+		counts, err := store.CountOutbox()
+		if err != nil {
+			respondError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data["outbox"] = counts
+	}
+
+	respondSuccess(w, "获取成功", data)
+}
+
+// handleReplayDelivery 重放投递记录（重置为待投递，由后台队列立即发送）
+func (s *Server) handleReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	entry, err := store.GetOutbox(id)
+	if err != nil {
+		respondError(w, fmt.Sprintf("查询投递记录失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if entry == nil {
+		respondError(w, "投递记录不存在", http.StatusNotFound)
+		return
+	}
+
+	if err := store.ReplayOutbox(id); err != nil {
+		respondError(w, fmt.Sprintf("重放失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Infof("[API] 重放 Webhook 投递 #%d (%s)", id, entry.Target)
+	s.recordAudit(r, fmt.Sprintf("重放 Webhook 投递 #%d", id), map[string]interface{}{"status": entry.Status, "attempts": entry.Attempts},
+		map[string]interface{}{"status": storage.OutboxPending, "attempts": 0})
+	respondSuccess(w, "已加入投递队列", nil)
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"dnsfailover/internal/config"
+	"dnsfailover/internal/storage"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// redactChannel 返回隐藏密钥与 SMTP 密码的通知通道副本，用于接口返回与审计日志
+func redactChannel(ch *storage.NotifyChannel) *storage.NotifyChannel {
+	if ch == nil {
+		return nil
+	}
+	redacted := *ch
+	redacted.Secret = config.MaskSecret(ch.Secret)
+	if ch.Email != nil {
+		email := *ch.Email
+		email.Password = config.MaskSecret(email.Password)
+		redacted.Email = &email
+	}
+	return &redacted
+}
+
+// keepEmailPassword 提交的 SMTP 密码为空或为占位符时沿用已保存的密码
+// 只在 SMTP 服务器与用户名未变时沿用，避免已保存的密码被发往其他服务器
+func keepEmailPassword(submitted, stored *storage.EmailConfig) {
+	if submitted == nil || stored == nil {
+		return
+	}
+	if submitted.Host != stored.Host || submitted.Username != stored.Username {
+		if submitted.Password == config.SecretMask {
+			submitted.Password = ""
+		}
+		return
+	}
+	submitted.Password = config.KeepSecret(submitted.Password, stored.Password)
+}
+
+// redactChannels 批量隐藏通知通道的密钥与 SMTP 密码
+func redactChannels(channels []*storage.NotifyChannel) []*storage.NotifyChannel {
+	redacted := make([]*storage.NotifyChannel, 0, len(channels))
+	for _, ch := range channels {
+		redacted = append(redacted, redactChannel(ch))
+	}
+	return redacted
+}
+
+// redactSchedule 返回隐藏 Webhook 签名密钥的定时任务副本，用于接口返回与审计日志
+func redactSchedule(task *storage.ScheduleTask) *storage.ScheduleTask {
+	if task == nil {
+		return nil
+	}
+	redacted := *task
+	redacted.WebhookSecret = config.MaskSecret(task.WebhookSecret)
+	return &redacted
+}
+
+// redactSchedules 批量隐藏定时任务的 Webhook 签名密钥
+func redactSchedules(tasks []*storage.ScheduleTask) []*storage.ScheduleTask {
+	redacted := make([]*storage.ScheduleTask, 0, len(tasks))
+	for _, task := range tasks {
+		redacted = append(redacted, redactSchedule(task))
+	}
+	return redacted
+}
+
+// sensitiveHeaderKeywords 请求头名称包含这些关键字时视为敏感
+var sensitiveHeaderKeywords = []string{"auth", "token", "secret", "key", "signature", "password", "cookie"}
+
+// redactDelivery 返回隐藏地址中的 Token 与敏感请求头的投递记录副本，用于接口返回
+// 机器人地址常把 Token 放在查询参数（钉钉 access_token、企业微信 key）或路径（Telegram、飞书）中
+func redactDelivery(entry *storage.OutboxEntry) *storage.OutboxEntry {
+	if entry == nil {
+		return nil
+	}
+	redacted := *entry
+	redacted.URL = redactURL(entry.URL)
+	if entry.Headers != nil {
+		redacted.Headers = make(map[string]string, len(entry.Headers))
+		for name, value := range entry.Headers {
+			if isSensitiveHeader(name) {
+				value = config.MaskSecret(value)
+			}
+			redacted.Headers[name] = value
+		}
+	}
+	return &redacted
+}
+
+// redactDeliveries 批量隐藏投递记录中的敏感信息
+func redactDeliveries(entries []*storage.OutboxEntry) []*storage.OutboxEntry {
+	redacted := make([]*storage.OutboxEntry, 0, len(entries))
+	for _, entry := range entries {
+		redacted = append(redacted, redactDelivery(entry))
+	}
+	return redacted
+}
+
+// redactURL 隐藏地址中的密码、全部查询参数值与疑似 Token 的路径段
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return config.SecretMask
+	}
+
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), config.SecretMask)
+		}
+	}
+
+	segments := strings.Split(u.Path, "/")
+	for i, segment := range segments {
+		if isTokenSegment(segment) {
+			segments[i] = config.SecretMask
+		}
+	}
+	u.Path = strings.Join(segments, "/")
+	u.RawPath = ""
+
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	params := make([]string, 0, len(keys))
+	for _, key := range keys {
+		params = append(params, url.QueryEscape(key)+"="+config.SecretMask)
+	}
+	u.RawQuery = strings.Join(params, "&")
+
+	// 占位符中的 * 不转义，保持可读
+	return strings.ReplaceAll(u.String(), "%2A", "*")
+}
+
+// isTokenSegment 判断路径段是否疑似 Token（较长且含数字，如 Telegram bot Token、飞书 Hook ID）
+func isTokenSegment(segment string) bool {
+	if len(segment) < 16 {
+		return false
+	}
+	return strings.IndexFunc(segment, unicode.IsDigit) >= 0
+}
+
+// isSensitiveHeader 判断请求头是否可能携带凭据
+func isSensitiveHeader(name string) bool {
+	lower := strings.ToLower(name)
+	for _, keyword := range sensitiveHeaderKeywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
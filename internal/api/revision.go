@@ -0,0 +1,135 @@
+package api
+
+import (
+	"dnsfailover/internal/audit"
+	"dnsfailover/internal/config"
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/storage"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// handleGetConfigRevisions 获取配置版本列表
+func (s *Server) handleGetConfigRevisions(w http.ResponseWriter, r *http.Request) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	revisions, err := store.GetConfigRevisions(limit)
+	if err != nil {
+		respondError(w, fmt.Sprintf("获取配置版本失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	respondSuccess(w, "获取成功", revisions)
+}
+
+// handleGetConfigRevision 获取指定配置版本的完整内容
+func (s *Server) handleGetConfigRevision(w http.ResponseWriter, r *http.Request) {
+	rev, ok := s.loadRevision(w, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+
+	redacted := *rev
+	redacted.Config = config.RedactFullConfig(rev.Config)
+	respondSuccess(w, "获取成功", &redacted)
+}
+
+// handleDiffConfigRevisions 比较两个配置版本
+// 参数: from, to（版本号）
+func (s *Server) handleDiffConfigRevisions(w http.ResponseWriter, r *http.Request) {
+	from, ok := s.loadRevision(w, r.URL.Query().Get("from"))
+	if !ok {
+		return
+	}
+	to, ok := s.loadRevision(w, r.URL.Query().Get("to"))
+	if !ok {
+		return
+	}
+
+	respondSuccess(w, "比较完成", map[string]interface{}{
+		"from":    from.ID,
+		"to":      to.ID,
+		"changes": redactChanges(audit.Diff(from.Config, to.Config)),
+	})
+}
+
+// handleRollbackConfig 回滚到指定配置版本（生成一个新版本并立即生效）
+func (s *Server) handleRollbackConfig(w http.ResponseWriter, r *http.Request) {
+	rev, ok := s.loadRevision(w, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+
+	store := storage.GetStorage()
+	before, _ := store.LoadConfig()
+	if before != nil {
+		// 版本中的 Webhook 密钥为空或为占位符时保留当前密钥
+		rev.Config.Webhook.Secret = config.KeepSecret(rev.Config.Webhook.Secret, before.Webhook.Secret)
+	}
+
+	// 旧版本可能不满足之后新增的校验规则，校验失败时拒绝回滚
+	if err := validateFullConfig(rev.Config); err != nil {
+		respondError(w, fmt.Sprintf("版本 #%d 的配置校验失败，无法回滚: %v", rev.ID, err), http.StatusBadRequest)
+		return
+	}
+
+	revision, err := store.SaveConfig(rev.Config, actorName(r), fmt.Sprintf("回滚到版本 #%d", rev.ID))
+	if err != nil {
+		respondError(w, fmt.Sprintf("回滚失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.applyConfig(rev.Config, revision)
+
+	logger.Infof("[API] 配置已回滚到版本 #%d (新版本 #%d)", rev.ID, revision)
+	s.recordAudit(r, fmt.Sprintf("回滚配置到版本 #%d", rev.ID), config.RedactFullConfig(before), config.RedactFullConfig(rev.Config))
+	respondSuccess(w, "回滚成功", map[string]interface{}{"revision": revision})
+}
+
+// redactChanges 隐藏配置变更中的 Webhook 密钥，仍保留其发生变更的记录
+func redactChanges(changes []audit.Change) []audit.Change {
+	for i, change := range changes {
+		if change.Path == "webhook.secret" {
+			before, _ := change.Before.(string)
+			after, _ := change.After.(string)
+			changes[i].Before = config.MaskSecret(before)
+			changes[i].After = config.MaskSecret(after)
+		}
+	}
+	return changes
+}
+
+// loadRevision 解析版本号并读取配置版本，失败时直接写入错误响应
+func (s *Server) loadRevision(w http.ResponseWriter, idStr string) (*storage.ConfigRevision, bool) {
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		respondError(w, fmt.Sprintf("无效的版本号: %s", idStr), http.StatusBadRequest)
+		return nil, false
+	}
+
+	rev, err := store.GetConfigRevision(id)
+	if err != nil {
+		respondError(w, fmt.Sprintf("查询配置版本失败: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	if rev == nil {
+		respondError(w, fmt.Sprintf("配置版本不存在: #%d", id), http.StatusNotFound)
+		return nil, false
+	}
+
+	return rev, true
+}
@@ -1,12 +1,13 @@
 package api
 
 import (
-	"bytes"
+	"dnsfailover/internal/auth"
 	"dnsfailover/internal/config"
 	"dnsfailover/internal/logger"
 	"dnsfailover/internal/monitor"
 	"dnsfailover/internal/schedule"
 	"dnsfailover/internal/storage"
+	"dnsfailover/internal/webhook"
 	"embed"
 	"encoding/json"
 	"fmt"
@@ -23,21 +24,20 @@ var webFS embed.FS
 
 // Server Web API 服务器
 type Server struct {
-	cfg             *config.Config
-	scheduler       *monitor.Scheduler
+	scheduler       *monitor.Scheduler // 当前配置由调度器持有，读写均通过调度器加锁进行
 	scheduleManager *schedule.Manager
+	configSource    *config.FileSource // 配置文件（GitOps 模式），nil 表示未启用
 	router          *mux.Router
 	server          *http.Server
-	mu              sync.RWMutex
+	mu              sync.RWMutex // 保护 configSource
 }
 
 // NewServer 创建 API 服务器
-func NewServer(cfg *config.Config, scheduler *monitor.Scheduler, scheduleManager *schedule.Manager, port int) *Server {
+func NewServer(scheduler *monitor.Scheduler, scheduleManager *schedule.Manager, port int) *Server {
 	s := &Server{
-		cfg:             cfg,
 		scheduler:       scheduler,
 		scheduleManager: scheduleManager,
-		router:          mux.NewRouter(),
+		router:          mux.NewRouter().UseEncodedPath(), // 检测目标可能是 URL，路径中以编码形式传递
 	}
 
 	// 注册路由
@@ -54,35 +54,91 @@ func NewServer(cfg *config.Config, scheduler *monitor.Scheduler, scheduleManager
 }
 
 // registerRoutes 注册路由
+// 每条路由声明所需的最低角色: viewer 只读, operator 日常运维, admin 修改配置
 func (s *Server) registerRoutes() {
-	// 启用 CORS
+	// 启用 CORS 与认证
 	s.router.Use(corsMiddleware)
+	s.router.Use(s.authMiddleware)
 
 	// 静态文件（前端页面）
 	s.router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir("./web/static"))))
-	s.router.HandleFunc("/", s.handleIndex).Methods("GET")
+	s.router.HandleFunc("/", s.require(auth.RoleViewer, s.handleIndex)).Methods("GET")
 
 	// API 路由
 	api := s.router.PathPrefix("/api").Subrouter()
-	api.HandleFunc("/config", s.handleGetConfig).Methods("GET")
-	api.HandleFunc("/config", s.handleUpdateConfig).Methods("POST")
-	api.HandleFunc("/status", s.handleGetStatus).Methods("GET")
-	api.HandleFunc("/domains", s.handleGetDomains).Methods("GET")
-	api.HandleFunc("/logs", s.handleGetLogs).Methods("GET")
-	api.HandleFunc("/logs/clear", s.handleClearLogs).Methods("POST")
+	api.HandleFunc("/me", s.require(auth.RoleViewer, s.handleGetMe)).Methods("GET")
+	api.HandleFunc("/config", s.require(auth.RoleViewer, s.handleGetConfig)).Methods("GET")
+	api.HandleFunc("/config", s.require(auth.RoleAdmin, s.unlessConfigFileManaged(s.handleUpdateConfig))).Methods("POST")
+	api.HandleFunc("/config/locks", s.require(auth.RoleViewer, s.handleGetConfigLocks)).Methods("GET")
+	api.HandleFunc("/config/revisions", s.require(auth.RoleViewer, s.handleGetConfigRevisions)).Methods("GET")
+	api.HandleFunc("/config/revisions/diff", s.require(auth.RoleViewer, s.handleDiffConfigRevisions)).Methods("GET")
+	api.HandleFunc("/config/revisions/{id:[0-9]+}", s.require(auth.RoleViewer, s.handleGetConfigRevision)).Methods("GET")
+	api.HandleFunc("/config/revisions/{id:[0-9]+}/rollback", s.require(auth.RoleAdmin, s.unlessConfigFileManaged(s.handleRollbackConfig))).Methods("POST")
+	api.HandleFunc("/status", s.require(auth.RoleViewer, s.handleGetStatus)).Methods("GET")
+	api.HandleFunc("/domains", s.require(auth.RoleViewer, s.handleGetDomains)).Methods("GET")
+	api.HandleFunc("/probe/stats", s.require(auth.RoleViewer, s.handleGetProbeStats)).Methods("GET")
+	api.HandleFunc("/logs", s.require(auth.RoleViewer, s.handleGetLogs)).Methods("GET")
+	api.HandleFunc("/logs/clear", s.require(auth.RoleOperator, s.handleClearLogs)).Methods("POST")
 
 	// 定时任务路由
-	api.HandleFunc("/schedules", s.handleGetSchedules).Methods("GET")
-	api.HandleFunc("/schedules", s.handleCreateSchedule).Methods("POST")
-	api.HandleFunc("/schedules/{id}", s.handleGetSchedule).Methods("GET")
-	api.HandleFunc("/schedules/{id}", s.handleUpdateSchedule).Methods("PUT")
-	api.HandleFunc("/schedules/{id}", s.handleDeleteSchedule).Methods("DELETE")
-	api.HandleFunc("/schedules/{id}/run", s.handleRunSchedule).Methods("POST")
-	api.HandleFunc("/schedules/{id}/enable", s.handleEnableSchedule).Methods("POST")
-	api.HandleFunc("/schedules/{id}/disable", s.handleDisableSchedule).Methods("POST")
-
-	// Webhook 测试路由
-	api.HandleFunc("/webhook/test", s.handleTestWebhook).Methods("POST")
+	api.HandleFunc("/schedules", s.require(auth.RoleViewer, s.handleGetSchedules)).Methods("GET")
+	api.HandleFunc("/schedules", s.require(auth.RoleAdmin, s.unlessSchedulesLocked(s.handleCreateSchedule))).Methods("POST")
+	api.HandleFunc("/schedules/{id}", s.require(auth.RoleViewer, s.handleGetSchedule)).Methods("GET")
+	api.HandleFunc("/schedules/{id}", s.require(auth.RoleAdmin, s.unlessSchedulesLocked(s.handleUpdateSchedule))).Methods("PUT")
+	api.HandleFunc("/schedules/{id}", s.require(auth.RoleAdmin, s.unlessSchedulesLocked(s.handleDeleteSchedule))).Methods("DELETE")
+	api.HandleFunc("/schedules/{id}/run", s.require(auth.RoleOperator, s.handleRunSchedule)).Methods("POST")
+	api.HandleFunc("/schedules/{id}/enable", s.require(auth.RoleOperator, s.unlessSchedulesLocked(s.handleEnableSchedule))).Methods("POST")
+	api.HandleFunc("/schedules/{id}/disable", s.require(auth.RoleOperator, s.unlessSchedulesLocked(s.handleDisableSchedule))).Methods("POST")
+
+	// Webhook 测试与投递记录路由
+	api.HandleFunc("/webhook/test", s.require(auth.RoleOperator, s.handleTestWebhook)).Methods("POST")
+	api.HandleFunc("/webhook/template/preview", s.require(auth.RoleViewer, s.handlePreviewTemplate)).Methods("POST")
+	api.HandleFunc("/webhook/stats", s.require(auth.RoleViewer, s.handleGetWebhookStats)).Methods("GET")
+	api.HandleFunc("/webhook/deliveries", s.require(auth.RoleViewer, s.handleGetDeliveries)).Methods("GET")
+	api.HandleFunc("/webhook/deliveries/{id:[0-9]+}/replay", s.require(auth.RoleOperator, s.handleReplayDelivery)).Methods("POST")
+
+	// 通知通道与路由规则 API
+	api.HandleFunc("/channels", s.require(auth.RoleViewer, s.handleGetChannels)).Methods("GET")
+	api.HandleFunc("/channels", s.require(auth.RoleAdmin, s.handleSaveChannel)).Methods("POST")
+	api.HandleFunc("/channels/{id}", s.require(auth.RoleAdmin, s.handleSaveChannel)).Methods("PUT")
+	api.HandleFunc("/channels/{id}", s.require(auth.RoleAdmin, s.handleDeleteChannel)).Methods("DELETE")
+	api.HandleFunc("/channels/{id}/test", s.require(auth.RoleOperator, s.handleTestChannel)).Methods("POST")
+	api.HandleFunc("/routes", s.require(auth.RoleViewer, s.handleGetRoutes)).Methods("GET")
+	api.HandleFunc("/routes", s.require(auth.RoleAdmin, s.handleSaveRoute)).Methods("POST")
+	api.HandleFunc("/routes/{id}", s.require(auth.RoleAdmin, s.handleSaveRoute)).Methods("PUT")
+	api.HandleFunc("/routes/{id}", s.require(auth.RoleAdmin, s.handleDeleteRoute)).Methods("DELETE")
+
+	// 升级策略与故障事件 API
+	api.HandleFunc("/escalations", s.require(auth.RoleViewer, s.handleGetEscalations)).Methods("GET")
+	api.HandleFunc("/escalations", s.require(auth.RoleAdmin, s.handleSaveEscalation)).Methods("POST")
+	api.HandleFunc("/escalations/{id}", s.require(auth.RoleAdmin, s.handleSaveEscalation)).Methods("PUT")
+	api.HandleFunc("/escalations/{id}", s.require(auth.RoleAdmin, s.handleDeleteEscalation)).Methods("DELETE")
+	api.HandleFunc("/incidents", s.require(auth.RoleViewer, s.handleGetIncidents)).Methods("GET")
+	api.HandleFunc("/incidents/{id:[0-9]+}", s.require(auth.RoleViewer, s.handleGetIncident)).Methods("GET")
+	api.HandleFunc("/incidents/{id:[0-9]+}/ack", s.require(auth.RoleOperator, s.handleAckIncident)).Methods("POST")
+
+	// 检测目标状态与静音 API（{target} 为 URL 编码后的目标）
+	api.HandleFunc("/targets", s.require(auth.RoleViewer, s.handleGetTargets)).Methods("GET")
+	api.HandleFunc("/targets/{target}/results", s.require(auth.RoleViewer, s.handleGetTargetResults)).Methods("GET")
+	api.HandleFunc("/targets/{target}/silence", s.require(auth.RoleOperator, s.handleSilenceTarget)).Methods("POST")
+	api.HandleFunc("/targets/{target}/silence", s.require(auth.RoleOperator, s.handleUnsilenceTarget)).Methods("DELETE")
+
+	// 维护窗口 API
+	api.HandleFunc("/maintenance", s.require(auth.RoleViewer, s.handleGetMaintenance)).Methods("GET")
+	api.HandleFunc("/maintenance", s.require(auth.RoleOperator, s.handleSaveMaintenance)).Methods("POST")
+	api.HandleFunc("/maintenance/{id}", s.require(auth.RoleOperator, s.handleSaveMaintenance)).Methods("PUT")
+	api.HandleFunc("/maintenance/{id}", s.require(auth.RoleOperator, s.handleDeleteMaintenance)).Methods("DELETE")
+
+	// 用户与 Token 管理路由
+	api.HandleFunc("/users", s.require(auth.RoleAdmin, s.handleGetUsers)).Methods("GET")
+	api.HandleFunc("/users", s.require(auth.RoleAdmin, s.handleSaveUser)).Methods("POST")
+	api.HandleFunc("/users/{username}", s.require(auth.RoleAdmin, s.handleDeleteUser)).Methods("DELETE")
+	api.HandleFunc("/tokens", s.require(auth.RoleAdmin, s.handleGetTokens)).Methods("GET")
+	api.HandleFunc("/tokens", s.require(auth.RoleAdmin, s.handleCreateToken)).Methods("POST")
+	api.HandleFunc("/tokens/{id}", s.require(auth.RoleAdmin, s.handleDeleteToken)).Methods("DELETE")
+
+	// 审计日志路由
+	api.HandleFunc("/audit", s.require(auth.RoleAdmin, s.handleGetAudit)).Methods("GET")
 }
 
 // Start 启动 API 服务器
@@ -107,7 +163,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -131,25 +187,25 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 }
 
 // printConfigSummary 打印配置摘要到日志
-func (s *Server) printConfigSummary() {
+func (s *Server) printConfigSummary(cfg *storage.FullConfig) {
 	pingStatus := "禁用"
-	if s.cfg.Ping.Enabled {
-		pingStatus = fmt.Sprintf("%d 个目标", len(s.cfg.Ping.Domains))
+	if cfg.Ping.Enabled {
+		pingStatus = fmt.Sprintf("%d 个目标", len(cfg.Ping.Domains))
 	}
 
 	tcpStatus := "禁用"
-	if s.cfg.Tcp.Enabled {
-		tcpStatus = fmt.Sprintf("%d 个目标", len(s.cfg.Tcp.Domains))
+	if cfg.Tcp.Enabled {
+		tcpStatus = fmt.Sprintf("%d 个目标", len(cfg.Tcp.Domains))
 	}
 
 	httpStatus := "禁用"
-	if s.cfg.Http.Enabled {
-		httpStatus = fmt.Sprintf("%d 个目标", len(s.cfg.Http.Domains))
+	if cfg.Http.Enabled {
+		httpStatus = fmt.Sprintf("%d 个目标", len(cfg.Http.Domains))
 	}
 
 	webhookStatus := "未配置"
-	if s.cfg.Webhook.URL != "" {
-		webhookStatus = s.cfg.Webhook.URL
+	if cfg.Webhook.URL != "" {
+		webhookStatus = cfg.Webhook.URL
 	}
 
 	logger.Infof("[API] ━━━━━━━━━━ 当前配置 ━━━━━━━━━━")
@@ -157,46 +213,54 @@ func (s *Server) printConfigSummary() {
 	logger.Infof("[API] TCP: %s", tcpStatus)
 	logger.Infof("[API] HTTP: %s", httpStatus)
 	logger.Infof("[API] Webhook: %s", webhookStatus)
-	logger.Infof("[API] 静默期: %d 秒", s.cfg.Webhook.SilencePeriod)
+	logger.Infof("[API] 静默期: %d 秒", cfg.Webhook.SilencePeriod)
 }
 
 // handleGetConfig 获取配置
 func (s *Server) handleGetConfig(w http.ResponseWriter, r *http.Request) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	cfg := s.scheduler.CurrentConfig()
 
 	// 检查是否需要打印日志（刷新操作）
 	if r.URL.Query().Get("refresh") == "true" {
-		s.printConfigSummary()
+		s.printConfigSummary(cfg)
 	}
 
+	// Webhook 密钥不返回明文
+	webhookCfg := cfg.Webhook
+	webhookCfg.Secret = config.MaskSecret(webhookCfg.Secret)
+
 	response := map[string]interface{}{
 		"ping": map[string]interface{}{
-			"enabled":            s.cfg.Ping.Enabled,
-			"frequency":          s.cfg.Ping.Frequency,
-			"failcount":          s.cfg.Ping.FailCount,
-			"timeout":            s.cfg.Ping.Timeout,
-			"retry":              s.cfg.Ping.Retry,
-			"remote_update_freq": s.cfg.Ping.RemoteUpdateFreq,
-			"domains":            s.cfg.Ping.Domains,
+			"enabled":            cfg.Ping.Enabled,
+			"frequency":          cfg.Ping.Frequency,
+			"failcount":          cfg.Ping.FailCount,
+			"recovery_count":     cfg.Ping.RecoveryCount,
+			"timeout":            cfg.Ping.Timeout,
+			"retry":              cfg.Ping.Retry,
+			"remote_update_freq": cfg.Ping.RemoteUpdateFreq,
+			"domains":            cfg.Ping.Domains,
 		},
 		"tcp": map[string]interface{}{
-			"enabled":   s.cfg.Tcp.Enabled,
-			"frequency": s.cfg.Tcp.Frequency,
-			"failcount": s.cfg.Tcp.FailCount,
-			"timeout":   s.cfg.Tcp.Timeout,
-			"retry":     s.cfg.Tcp.Retry,
-			"domains":   s.cfg.Tcp.Domains,
+			"enabled":        cfg.Tcp.Enabled,
+			"frequency":      cfg.Tcp.Frequency,
+			"failcount":      cfg.Tcp.FailCount,
+			"recovery_count": cfg.Tcp.RecoveryCount,
+			"timeout":        cfg.Tcp.Timeout,
+			"retry":          cfg.Tcp.Retry,
+			"domains":        cfg.Tcp.Domains,
 		},
 		"http": map[string]interface{}{
-			"enabled":   s.cfg.Http.Enabled,
-			"frequency": s.cfg.Http.Frequency,
-			"failcount": s.cfg.Http.FailCount,
-			"timeout":   s.cfg.Http.Timeout,
-			"retry":     s.cfg.Http.Retry,
-			"domains":   s.cfg.Http.Domains,
+			"enabled":        cfg.Http.Enabled,
+			"frequency":      cfg.Http.Frequency,
+			"failcount":      cfg.Http.FailCount,
+			"recovery_count": cfg.Http.RecoveryCount,
+			"timeout":        cfg.Http.Timeout,
+			"retry":          cfg.Http.Retry,
+			"domains":        cfg.Http.Domains,
 		},
-		"webhook": s.cfg.Webhook,
+		"webhook": webhookCfg,
+		"targets": cfg.Targets,
+		"flap":    cfg.Flap,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -206,6 +270,14 @@ func (s *Server) handleGetConfig(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// validateFullConfig 校验配置、填充默认值并试渲染告警模板（保存与回滚共用）
+func validateFullConfig(cfg *storage.FullConfig) error {
+	if err := config.NormalizeFullConfig(cfg); err != nil {
+		return err
+	}
+	return webhook.ValidateTemplate(cfg.Webhook.Template)
+}
+
 // handleUpdateConfig 更新配置
 func (s *Server) handleUpdateConfig(w http.ResponseWriter, r *http.Request) {
 	var req storage.FullConfig
@@ -214,84 +286,70 @@ func (s *Server) handleUpdateConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 验证配置 - 至少启用一种探针
-	if !req.Ping.Enabled && !req.Tcp.Enabled && !req.Http.Enabled {
-		respondError(w, "至少需要启用一种探针 (ping/tcp/http)", http.StatusBadRequest)
-		return
-	}
+	// 接口返回的 Webhook 密钥已隐藏，提交为空或占位符时保留原值
+	req.Webhook.Secret = config.KeepSecret(req.Webhook.Secret, s.scheduler.CurrentConfig().Webhook.Secret)
 
-	// 设置默认值
-	setProbeDefaults(&req.Ping)
-	setProbeDefaults(&req.Tcp)
-	setProbeDefaults(&req.Http)
-	if req.Webhook.Method == "" {
-		req.Webhook.Method = "POST"
-	}
-	if req.Webhook.Timeout == 0 {
-		req.Webhook.Timeout = 10
-	}
-	if req.Webhook.SilencePeriod == 0 {
-		req.Webhook.SilencePeriod = 60 // 默认 60 秒静默期
+	// 验证配置并设置默认值
+	if err := validateFullConfig(&req); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	// 保存到 SQLite
+	// 保存到 SQLite（同时生成新的配置版本）
+	var before *storage.FullConfig
+	var revision int64
 	store := storage.GetStorage()
 	if store != nil {
-		if err := store.SaveConfig(&req); err != nil {
+		before, _ = store.LoadConfig()
+		var err error
+		revision, err = store.SaveConfig(&req, actorName(r), r.URL.Query().Get("comment"))
+		if err != nil {
 			respondError(w, fmt.Sprintf("保存配置失败: %v", err), http.StatusInternalServerError)
 			return
 		}
 	}
 
-	// 应用配置到内存
-	s.mu.Lock()
-	config.ApplyStorageConfig(s.cfg, &req)
-	s.mu.Unlock()
-
-	// 更新全局静默期
-	if req.Webhook.SilencePeriod > 0 {
-		monitor.DefaultSilenceDuration = time.Duration(req.Webhook.SilencePeriod) * time.Second
-		logger.Infof("[API] 静默期已更新为 %d 秒", req.Webhook.SilencePeriod)
-	}
+	// 应用配置到内存并热更新调度器
+	s.applyConfig(&req, revision)
 
-	logger.Info("[API] 配置已更新并保存到数据库")
+	logger.Infof("[API] 配置已更新并保存到数据库 (版本 #%d)", revision)
+	s.recordAudit(r, "更新配置", config.RedactFullConfig(before), config.RedactFullConfig(&req))
 
-	respondSuccess(w, "配置更新成功", nil)
+	respondSuccess(w, "配置更新成功", map[string]interface{}{"revision": revision})
 }
 
-// setProbeDefaults 设置探针默认值
-func setProbeDefaults(cfg *storage.ProbeConfig) {
-	if cfg.Timeout == 0 {
-		cfg.Timeout = 5
-	}
-	if cfg.Retry == 0 {
-		cfg.Retry = 3
-	}
-	if cfg.Frequency == 0 {
-		cfg.Frequency = 30
-	}
-	if cfg.FailCount == 0 {
-		cfg.FailCount = 3
-	}
+// applyConfig 将配置热更新到监控调度器
+func (s *Server) applyConfig(cfg *storage.FullConfig, revision int64) {
+	s.scheduler.ApplyConfig(cfg, revision)
 }
 
 // handleGetStatus 获取运行状态
 func (s *Server) handleGetStatus(w http.ResponseWriter, r *http.Request) {
+	cfg := s.scheduler.CurrentConfig()
 	status := map[string]interface{}{
 		"running":      s.scheduler.IsRunning(),
 		"timestamp":    time.Now().Unix(),
-		"ping_enabled": s.cfg.Ping.Enabled,
-		"ping_count":   len(s.cfg.Ping.Domains),
-		"tcp_enabled":  s.cfg.Tcp.Enabled,
-		"tcp_count":    len(s.cfg.Tcp.Domains),
-		"http_enabled": s.cfg.Http.Enabled,
-		"http_count":   len(s.cfg.Http.Domains),
-		"webhook_url":  s.cfg.Webhook.URL,
+		"ping_enabled": cfg.Ping.Enabled,
+		"ping_count":   len(cfg.Ping.Domains),
+		"tcp_enabled":  cfg.Tcp.Enabled,
+		"tcp_count":    len(cfg.Tcp.Domains),
+		"http_enabled": cfg.Http.Enabled,
+		"http_count":   len(cfg.Http.Domains),
+		"webhook_url":  cfg.Webhook.URL,
 	}
 
 	respondSuccess(w, "获取状态成功", status)
 }
 
+// handleGetProbeStats 获取检测工作池的并发与排队统计
+func (s *Server) handleGetProbeStats(w http.ResponseWriter, r *http.Request) {
+	if s.scheduler == nil {
+		respondError(w, "监控服务未启动", http.StatusServiceUnavailable)
+		return
+	}
+	respondSuccess(w, "获取成功", s.scheduler.ProbeStats())
+}
+
 // handleGetDomains 获取所有域名状态
 func (s *Server) handleGetDomains(w http.ResponseWriter, r *http.Request) {
 	// TODO: 从 StateManager 获取域名状态
@@ -342,6 +400,7 @@ func (s *Server) handleClearLogs(w http.ResponseWriter, r *http.Request) {
 
 	buffer.Clear()
 	logger.Info("[API] 内存日志已清空")
+	s.recordAudit(r, "清空内存日志", nil, nil)
 	respondSuccess(w, "日志清理成功", nil)
 }
 
@@ -369,15 +428,16 @@ func respondError(w http.ResponseWriter, message string, code int) {
 
 // ScheduleTaskRequest 定时任务请求结构
 type ScheduleTaskRequest struct {
-	Name        string            `json:"name"`
-	Enabled     bool              `json:"enabled"`
-	Cron        string            `json:"cron"`
-	CheckType   string            `json:"check_type"`
-	Target      string            `json:"target"`
-	Port        int               `json:"port"`
-	Timeout     int               `json:"timeout"`
-	WebhookURL  string            `json:"webhook_url"`
-	WebhookData map[string]string `json:"webhook_data"`
+	Name          string            `json:"name"`
+	Enabled       bool              `json:"enabled"`
+	Cron          string            `json:"cron"`
+	CheckType     string            `json:"check_type"`
+	Target        string            `json:"target"`
+	Port          int               `json:"port"`
+	Timeout       int               `json:"timeout"`
+	WebhookURL    string            `json:"webhook_url"`
+	WebhookData   map[string]string `json:"webhook_data"`
+	WebhookSecret string            `json:"webhook_secret"`
 }
 
 // handleGetSchedules 获取所有定时任务
@@ -394,7 +454,7 @@ func (s *Server) handleGetSchedules(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondSuccess(w, "获取成功", tasks)
+	respondSuccess(w, "获取成功", redactSchedules(tasks))
 }
 
 // handleCreateSchedule 创建定时任务
@@ -405,40 +465,27 @@ func (s *Server) handleCreateSchedule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 验证必填字段
-	if req.Name == "" {
-		respondError(w, "任务名称不能为空", http.StatusBadRequest)
-		return
-	}
-	if req.Cron == "" {
-		respondError(w, "Cron 表达式不能为空", http.StatusBadRequest)
-		return
-	}
-	if req.Target == "" {
-		respondError(w, "检测目标不能为空", http.StatusBadRequest)
-		return
-	}
-	if req.CheckType == "" {
-		req.CheckType = "ping"
-	}
-	if req.Timeout == 0 {
-		req.Timeout = 5
-	}
-
 	now := time.Now().Format("2006-01-02 15:04:05")
 	task := &storage.ScheduleTask{
-		ID:          uuid.New().String(),
-		Name:        req.Name,
-		Enabled:     req.Enabled,
-		Cron:        req.Cron,
-		CheckType:   req.CheckType,
-		Target:      req.Target,
-		Port:        req.Port,
-		Timeout:     req.Timeout,
-		WebhookURL:  req.WebhookURL,
-		WebhookData: req.WebhookData,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:            uuid.New().String(),
+		Name:          req.Name,
+		Enabled:       req.Enabled,
+		Cron:          req.Cron,
+		CheckType:     req.CheckType,
+		Target:        req.Target,
+		Port:          req.Port,
+		Timeout:       req.Timeout,
+		WebhookURL:    req.WebhookURL,
+		WebhookData:   req.WebhookData,
+		WebhookSecret: req.WebhookSecret,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	// 验证必填字段并设置默认值
+	if err := config.NormalizeScheduleTask(task); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	// 保存到数据库
@@ -457,7 +504,8 @@ func (s *Server) handleCreateSchedule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logger.Infof("[API] 创建定时任务: %s (%s)", task.Name, task.ID)
-	respondSuccess(w, "创建成功", task)
+	s.recordAudit(r, "创建定时任务", nil, redactSchedule(task))
+	respondSuccess(w, "创建成功", redactSchedule(task))
 }
 
 // handleGetSchedule 获取单个定时任务
@@ -476,7 +524,7 @@ func (s *Server) handleGetSchedule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondSuccess(w, "获取成功", task)
+	respondSuccess(w, "获取成功", redactSchedule(task))
 }
 
 // handleUpdateSchedule 更新定时任务
@@ -501,6 +549,8 @@ func (s *Server) handleUpdateSchedule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := *existing
+
 	// 更新字段
 	existing.Name = req.Name
 	existing.Enabled = req.Enabled
@@ -511,8 +561,14 @@ func (s *Server) handleUpdateSchedule(w http.ResponseWriter, r *http.Request) {
 	existing.Timeout = req.Timeout
 	existing.WebhookURL = req.WebhookURL
 	existing.WebhookData = req.WebhookData
+	existing.WebhookSecret = config.KeepSecret(req.WebhookSecret, existing.WebhookSecret)
 	existing.UpdatedAt = time.Now().Format("2006-01-02 15:04:05")
 
+	if err := config.NormalizeScheduleTask(existing); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if err := store.SaveScheduleTask(existing); err != nil {
 		respondError(w, fmt.Sprintf("保存失败: %v", err), http.StatusInternalServerError)
 		return
@@ -525,7 +581,8 @@ func (s *Server) handleUpdateSchedule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logger.Infof("[API] 更新定时任务: %s (%s)", existing.Name, existing.ID)
-	respondSuccess(w, "更新成功", existing)
+	s.recordAudit(r, "更新定时任务", redactSchedule(&before), redactSchedule(existing))
+	respondSuccess(w, "更新成功", redactSchedule(existing))
 }
 
 // handleDeleteSchedule 删除定时任务
@@ -534,6 +591,7 @@ func (s *Server) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
 	id := vars["id"]
 
 	store := storage.GetStorage()
+	before, _ := store.GetScheduleTask(id)
 	if err := store.DeleteScheduleTask(id); err != nil {
 		respondError(w, fmt.Sprintf("删除失败: %v", err), http.StatusInternalServerError)
 		return
@@ -545,6 +603,7 @@ func (s *Server) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logger.Infof("[API] 删除定时任务: %s", id)
+	s.recordAudit(r, "删除定时任务", redactSchedule(before), nil)
 	respondSuccess(w, "删除成功", nil)
 }
 
@@ -558,12 +617,13 @@ func (s *Server) handleRunSchedule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := s.scheduleManager.RunTaskNow(id)
+	result, err := s.scheduleManager.RunTaskNow(r.Context(), id)
 	if err != nil {
 		respondError(w, fmt.Sprintf("执行失败: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	s.recordAudit(r, "手动执行定时任务", nil, result)
 	respondSuccess(w, "执行完成", result)
 }
 
@@ -579,6 +639,7 @@ func (s *Server) handleEnableSchedule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := *task
 	task.Enabled = true
 	task.UpdatedAt = time.Now().Format("2006-01-02 15:04:05")
 	store.SaveScheduleTask(task)
@@ -587,6 +648,8 @@ func (s *Server) handleEnableSchedule(w http.ResponseWriter, r *http.Request) {
 		s.scheduleManager.EnableTask(id)
 	}
 
+	s.recordAudit(r, "启用定时任务", redactSchedule(&before), redactSchedule(task))
+
 	respondSuccess(w, "已启用", nil)
 }
 
@@ -602,6 +665,7 @@ func (s *Server) handleDisableSchedule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := *task
 	task.Enabled = false
 	task.UpdatedAt = time.Now().Format("2006-01-02 15:04:05")
 	store.SaveScheduleTask(task)
@@ -610,22 +674,25 @@ func (s *Server) handleDisableSchedule(w http.ResponseWriter, r *http.Request) {
 		s.scheduleManager.DisableTask(id)
 	}
 
+	s.recordAudit(r, "禁用定时任务", redactSchedule(&before), redactSchedule(task))
+
 	respondSuccess(w, "已禁用", nil)
 }
 
 // convertToScheduleTask 转换存储任务到调度任务
 func convertToScheduleTask(st *storage.ScheduleTask) *schedule.Task {
 	task := &schedule.Task{
-		ID:          st.ID,
-		Name:        st.Name,
-		Enabled:     st.Enabled,
-		Cron:        st.Cron,
-		CheckType:   schedule.CheckType(st.CheckType),
-		Target:      st.Target,
-		Port:        st.Port,
-		Timeout:     st.Timeout,
-		WebhookURL:  st.WebhookURL,
-		WebhookData: st.WebhookData,
+		ID:            st.ID,
+		Name:          st.Name,
+		Enabled:       st.Enabled,
+		Cron:          st.Cron,
+		CheckType:     schedule.CheckType(st.CheckType),
+		Target:        st.Target,
+		Port:          st.Port,
+		Timeout:       st.Timeout,
+		WebhookURL:    st.WebhookURL,
+		WebhookData:   st.WebhookData,
+		WebhookSecret: st.WebhookSecret,
 	}
 
 	if st.CreatedAt != "" {
@@ -646,10 +713,15 @@ func convertToScheduleTask(st *storage.ScheduleTask) *schedule.Task {
 // handleTestWebhook 测试 Webhook 发送
 func (s *Server) handleTestWebhook(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		URL     string            `json:"url"`
-		Method  string            `json:"method"`
-		Timeout int               `json:"timeout"`
-		Headers map[string]string `json:"headers"`
+		ChannelID string               `json:"channel_id"` // 已保存通道的 ID，表单中的密钥或密码已隐藏时沿用该通道的配置
+		Type      string               `json:"type"`
+		URL       string               `json:"url"`
+		Secret    string               `json:"secret"`
+		Template  string               `json:"template"`
+		Method    string               `json:"method"`
+		Timeout   int                  `json:"timeout"`
+		Headers   map[string]string    `json:"headers"`
+		Email     *storage.EmailConfig `json:"email"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -657,26 +729,35 @@ func (s *Server) handleTestWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.URL == "" {
-		respondError(w, "Webhook URL 不能为空", http.StatusBadRequest)
-		return
+	// 已保存的密钥只用于发往原地址的测试，SMTP 密码只用于原服务器，避免被转发到其他地址
+	if req.ChannelID == "" {
+		if current := s.scheduler.CurrentConfig(); current.Webhook.URL == req.URL {
+			req.Secret = config.KeepSecret(req.Secret, current.Webhook.Secret)
+		}
+	} else {
+		if store := storage.GetStorage(); store != nil {
+			if ch, err := store.GetChannel(req.ChannelID); err == nil && ch != nil {
+				if ch.URL == req.URL {
+					req.Secret = config.KeepSecret(req.Secret, ch.Secret)
+				}
+				keepEmailPassword(req.Email, ch.Email)
+			}
+		}
 	}
 
-	// 构建测试数据
-	testData := map[string]interface{}{
-		"type":       "test",
-		"probe_type": "test",
-		"target":     "test.example.com",
-		"fail_count": 0,
-		"threshold":  3,
-		"error":      "",
-		"timestamp":  time.Now().Unix(),
-		"message":    "这是一条 Webhook 测试消息",
+	// 邮件通道按通道规则校验 SMTP 配置，其余类型需要 URL
+	if req.Type == webhook.FormatEmail {
+		ch := storage.NotifyChannel{Name: "test", Type: req.Type, Email: req.Email}
+		if err := config.NormalizeChannel(&ch); err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Email = ch.Email
+	} else if req.URL == "" {
+		respondError(w, "Webhook URL 不能为空", http.StatusBadRequest)
+		return
 	}
 
-	body, _ := json.Marshal(testData)
-
-	// 创建请求
 	method := req.Method
 	if method == "" {
 		method = "POST"
@@ -687,31 +768,21 @@ func (s *Server) handleTestWebhook(w http.ResponseWriter, r *http.Request) {
 		timeout = 10
 	}
 
-	client := &http.Client{Timeout: time.Duration(timeout) * time.Second}
-
-	httpReq, err := http.NewRequest(method, req.URL, bytes.NewReader(body))
-	if err != nil {
-		respondError(w, "创建请求失败: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	for key, value := range req.Headers {
-		httpReq.Header.Set(key, value)
-	}
-
-	// 发送请求
-	resp, err := client.Do(httpReq)
+	err := webhook.SendTest(&webhook.Destination{
+		Type:     req.Type,
+		URL:      req.URL,
+		Secret:   req.Secret,
+		Template: req.Template,
+		Method:   method,
+		Headers:  req.Headers,
+		Timeout:  timeout,
+		Email:    req.Email,
+	})
 	if err != nil {
 		respondError(w, "发送失败: "+err.Error(), http.StatusBadGateway)
 		return
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		respondError(w, fmt.Sprintf("目标返回错误: %d %s", resp.StatusCode, resp.Status), http.StatusBadGateway)
-		return
-	}
 
-	respondSuccess(w, fmt.Sprintf("发送成功，目标返回: %d %s", resp.StatusCode, resp.Status), nil)
+	s.recordAudit(r, "测试 Webhook", nil, map[string]interface{}{"type": req.Type, "url": req.URL, "method": method})
+	respondSuccess(w, "发送成功", nil)
 }
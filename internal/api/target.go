@@ -0,0 +1,168 @@
+package api
+
+import (
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/maintenance"
+	"dnsfailover/internal/monitor"
+	"dnsfailover/internal/silence"
+	"dnsfailover/internal/storage"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// ========== 检测目标状态与静音 API ==========
+
+// targetView 检测目标状态，附带静音与维护窗口信息
+type targetView struct {
+	*monitor.TargetStatus
+	Silence     *storage.TargetSilence `json:"silence"`     // 当前静音，未静音时为 null
+	Maintenance string                 `json:"maintenance"` // 当前所处的维护窗口名称
+}
+
+// handleGetTargets 获取所有检测目标的运行时状态
+func (s *Server) handleGetTargets(w http.ResponseWriter, r *http.Request) {
+	if s.scheduler == nil {
+		respondError(w, "监控服务未启动", http.StatusServiceUnavailable)
+		return
+	}
+
+	statuses := s.scheduler.TargetStatuses()
+	views := make([]targetView, 0, len(statuses))
+	for _, status := range statuses {
+		view := targetView{TargetStatus: status, Silence: silence.Active(status.Target)}
+		if mw := maintenance.ActiveFor(status.Target, status.Tags); mw != nil {
+			view.Maintenance = mw.Name
+		}
+		views = append(views, view)
+	}
+
+	respondSuccess(w, "获取成功", views)
+}
+
+// handleGetTargetResults 获取检测目标最近的结构化检测结果（按时间倒序）
+// 查询参数: limit 返回条数（默认 100）；since 起始时间，格式 2006-01-02 15:04:05
+func (s *Server) handleGetTargetResults(w http.ResponseWriter, r *http.Request) {
+	target, ok := s.targetFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	results, err := store.QueryCheckResults(target, r.URL.Query().Get("since"), limit)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondSuccess(w, "获取成功", results)
+}
+
+// handleSilenceTarget 静音检测目标
+// 路径中的目标需 URL 编码；请求体: {"duration": "30m", "reason": "升级中"}
+func (s *Server) handleSilenceTarget(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Duration string `json:"duration"`
+		Reason   string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "无效的 JSON 格式", http.StatusBadRequest)
+		return
+	}
+
+	target, ok := s.targetFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		respondError(w, fmt.Sprintf("无效的静音时长: %q（示例: 30m, 2h）", req.Duration), http.StatusBadRequest)
+		return
+	}
+	ts, err := silence.New(target, duration, req.Reason, actorName(r))
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	before, _ := store.GetTargetSilence(target)
+	if err := store.SaveTargetSilence(ts); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	silence.Invalidate()
+
+	logger.Infof("[API] 静音目标 %s 至 %s: %s", target, ts.ExpiresAt, ts.Reason)
+	s.recordAudit(r, "静音目标", before, ts)
+	respondSuccess(w, "已静音", ts)
+}
+
+// handleUnsilenceTarget 提前解除目标静音
+func (s *Server) handleUnsilenceTarget(w http.ResponseWriter, r *http.Request) {
+	target, ok := s.targetFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	store := storage.GetStorage()
+	if store == nil {
+		respondError(w, "数据库未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	before, err := store.GetTargetSilence(target)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if before == nil {
+		respondError(w, "目标未静音", http.StatusNotFound)
+		return
+	}
+
+	if err := store.DeleteTargetSilence(target); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	silence.Invalidate()
+
+	logger.Infof("[API] 解除目标静音: %s", target)
+	s.recordAudit(r, "解除目标静音", before, nil)
+	respondSuccess(w, "已解除静音", nil)
+}
+
+// targetFromPath 解析路径中的目标并校验其在当前配置中存在
+func (s *Server) targetFromPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	target, err := url.PathUnescape(mux.Vars(r)["target"])
+	if err != nil {
+		respondError(w, "无效的目标", http.StatusBadRequest)
+		return "", false
+	}
+
+	if s.scheduler != nil {
+		for _, status := range s.scheduler.TargetStatuses() {
+			if status.Target == target {
+				return target, true
+			}
+		}
+	}
+	respondError(w, fmt.Sprintf("检测目标不存在: %s", target), http.StatusNotFound)
+	return "", false
+}
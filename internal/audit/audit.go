@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/storage"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// 操作来源
+const (
+	SourceAPI  = "api"
+	SourceCLI  = "cli"
+	SourceFile = "file"
+)
+
+// Entry 一条待记录的审计事件
+type Entry struct {
+	Actor    string      // 操作者（用户名/Token 名称/cli）
+	Source   string      // 来源: api | cli | file
+	SourceIP string      // 来源 IP（CLI 为空）
+	Method   string      // HTTP 方法（CLI 为命令名）
+	Endpoint string      // 请求路径或 CLI 命令
+	Action   string      // 操作描述
+	Before   interface{} // 变更前的对象（可为 nil）
+	After    interface{} // 变更后的对象（可为 nil）
+}
+
+// Change 单个字段的变更
+type Change struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Record 写入审计日志，失败只记录日志不影响业务
+func Record(e *Entry) {
+	store := storage.GetStorage()
+	if store == nil {
+		return
+	}
+
+	before := toJSON(e.Before)
+	after := toJSON(e.After)
+	diff, _ := json.Marshal(Diff(e.Before, e.After))
+
+	err := store.InsertAuditLog(&storage.AuditLog{
+		Actor:     e.Actor,
+		Source:    e.Source,
+		SourceIP:  e.SourceIP,
+		Method:    e.Method,
+		Endpoint:  e.Endpoint,
+		Action:    e.Action,
+		Before:    before,
+		After:     after,
+		Diff:      string(diff),
+		CreatedAt: time.Now().Format("2006-01-02 15:04:05"),
+	})
+	if err != nil {
+		logger.Errorf("[AUDIT] 写入审计日志失败: %v", err)
+	}
+}
+
+// Diff 比较两个对象的 JSON 表示，返回按路径排序的字段级变更
+func Diff(before, after interface{}) []Change {
+	changes := []Change{}
+	diffValue("", normalize(before), normalize(after), &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// diffValue 递归比较：对象逐键比较，其余类型整体比较
+func diffValue(path string, before, after interface{}, changes *[]Change) {
+	bm, bok := before.(map[string]interface{})
+	am, aok := after.(map[string]interface{})
+	if bok && aok {
+		keys := make(map[string]bool)
+		for k := range bm {
+			keys[k] = true
+		}
+		for k := range am {
+			keys[k] = true
+		}
+		for k := range keys {
+			sub := k
+			if path != "" {
+				sub = path + "." + k
+			}
+			diffValue(sub, bm[k], am[k], changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		if path == "" {
+			path = "$"
+		}
+		*changes = append(*changes, Change{Path: path, Before: before, After: after})
+	}
+}
+
+// normalize 通过 JSON 往返转换为通用结构，便于比较
+func normalize(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out interface{}
+	json.Unmarshal(data, &out)
+	return out
+}
+
+// toJSON 序列化对象，nil 返回空字符串
+func toJSON(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
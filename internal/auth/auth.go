@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Role 访问角色
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // 只读：查看状态、配置、日志
+	RoleOperator Role = "operator" // 运维：执行定时任务、确认事件等日常操作
+	RoleAdmin    Role = "admin"    // 管理员：修改配置、删除目标、管理用户
+)
+
+// roleLevels 角色权限等级，等级高的角色包含等级低的全部权限
+var roleLevels = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// ParseRole 解析角色名称
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := roleLevels[role]; !ok {
+		return "", fmt.Errorf("无效的角色: %s (可选: viewer/operator/admin)", s)
+	}
+	return role, nil
+}
+
+// Allows 判断当前角色是否满足所需角色
+func (r Role) Allows(required Role) bool {
+	return roleLevels[r] >= roleLevels[required]
+}
+
+// Principal 已认证的访问主体
+type Principal struct {
+	Name string `json:"name"` // 用户名或 Token 名称
+	Kind string `json:"kind"` // user | token | anonymous
+	Role Role   `json:"role"` // 角色
+}
+
+type principalKey struct{}
+
+// WithPrincipal 将访问主体写入 context
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext 从 context 中获取访问主体
+func FromContext(ctx context.Context) *Principal {
+	if p, ok := ctx.Value(principalKey{}).(*Principal); ok {
+		return p
+	}
+	return nil
+}
+
+const (
+	passwordIterations = 100000
+	passwordKeyLen     = 32
+)
+
+// HashPassword 使用 PBKDF2-SHA256 计算密码哈希
+// 格式: pbkdf2-sha256$<迭代次数>$<盐>$<哈希>
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("生成盐失败: %w", err)
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeyLen)
+	if err != nil {
+		return "", fmt.Errorf("计算密码哈希失败: %w", err)
+	}
+
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passwordIterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// CheckPassword 校验密码是否与哈希匹配
+func CheckPassword(password, encoded string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(expected))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(key, expected) == 1
+}
+
+// GenerateToken 生成随机 API Token（明文仅在创建时返回一次）
+func GenerateToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成 Token 失败: %w", err)
+	}
+	return "dfa_" + hex.EncodeToString(buf), nil
+}
+
+// HashToken 计算 Token 的存储哈希
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -9,22 +9,86 @@ import (
 
 // Config 主配置结构
 type Config struct {
-	Ping    ProbeConfig
-	Tcp     ProbeConfig
-	Http    ProbeConfig
-	Webhook WebhookConfig
-	Log     LogConfig
-	DBPath  string // SQLite 数据库路径
+	Ping     ProbeConfig
+	Tcp      ProbeConfig
+	Http     ProbeConfig
+	Webhook  WebhookConfig
+	Targets  map[string]TargetMeta // 目标标签与告警级别，按目标地址索引
+	Flap     FlapConfig
+	Dispatch DispatchConfig
+	Probe    ProbePoolConfig
+	Log      LogConfig
+	DBPath   string // SQLite 数据库路径
 }
 
 // WebhookConfig Webhook 回调配置
 type WebhookConfig struct {
-	URL           string            `json:"url"`
-	Method        string            `json:"method"`
-	Headers       map[string]string `json:"headers"`
-	Timeout       int               `json:"timeout"`
-	Retry         int               `json:"retry"`
-	SilencePeriod int               `json:"silence_period"` // 静默期（秒），发送告警后暂停检测的时间
+	Type             string            `json:"type"` // 消息格式: webhook/dingtalk/feishu/slack/telegram/wecom
+	URL              string            `json:"url"`
+	Secret           string            `json:"secret"` // 共享密钥：钉钉/飞书加签，并用于 X-Signature 签名
+	Method           string            `json:"method"`
+	Headers          map[string]string `json:"headers"`
+	Template         string            `json:"template"` // 自定义消息体模板（text/template）
+	Timeout          int               `json:"timeout"`
+	Retry            int               `json:"retry"`
+	SilencePeriod    int               `json:"silence_period"`    // 静默期（秒），发送故障告警后不重复告警的时间
+	ReminderInterval int               `json:"reminder_interval"` // 持续故障时的提醒间隔（秒），0 表示不发送提醒
+}
+
+// TargetMeta 检测目标的附加信息，用于告警路由
+type TargetMeta struct {
+	Tags          []string     `json:"tags,omitempty"`
+	Severity      string       `json:"severity,omitempty"`       // 告警级别，默认 critical
+	RecoveryCount int          `json:"recovery_count,omitempty"` // 恢复阈值，0 表示沿用探针配置
+	Thresholds    *Thresholds  `json:"thresholds,omitempty"`     // 性能降级阈值
+	DependsOn     []string     `json:"depends_on,omitempty"`     // 上游依赖目标，上游故障时抑制本目标告警
+	Ping          *PingOptions `json:"ping,omitempty"`           // Ping 检测参数
+}
+
+// PingOptions Ping 检测参数（0 表示使用默认值）
+type PingOptions struct {
+	Count         int `json:"count,omitempty"`          // 发送包数，默认 4
+	Interval      int `json:"interval,omitempty"`       // 发包间隔（毫秒），默认 300
+	Size          int `json:"size,omitempty"`           // 负载大小（字节），默认 24，不能小于 24
+	TTL           int `json:"ttl,omitempty"`            // 默认 64
+	LossThreshold int `json:"loss_threshold,omitempty"` // 丢包率达到该值（%）判定失败，默认 100 即全部丢包才失败
+}
+
+// Thresholds 性能降级阈值，超过 warning/critical 阈值时目标进入降级状态（0 表示不检查）
+type Thresholds struct {
+	LatencyWarning  int `json:"latency_warning,omitempty"`  // 延迟（毫秒）
+	LatencyCritical int `json:"latency_critical,omitempty"` // 延迟（毫秒）
+	LossWarning     int `json:"loss_warning,omitempty"`     // 丢包率（%，仅 Ping）
+	LossCritical    int `json:"loss_critical,omitempty"`    // 丢包率（%，仅 Ping）
+	JitterWarning   int `json:"jitter_warning,omitempty"`   // 抖动（毫秒）
+	JitterCritical  int `json:"jitter_critical,omitempty"`  // 抖动（毫秒）
+}
+
+// FlapConfig 抖动检测配置
+// 在最近 Window 次检测结果中统计状态变化率，达到 HighThreshold% 判定为抖动，
+// 低于 LowThreshold% 判定为已稳定
+type FlapConfig struct {
+	Enabled       bool `json:"enabled"`
+	Window        int  `json:"window"`         // 统计的检测次数
+	HighThreshold int  `json:"high_threshold"` // 进入抖动的状态变化率（%）
+	LowThreshold  int  `json:"low_threshold"`  // 退出抖动的状态变化率（%）
+}
+
+// DispatchConfig 告警分发队列配置
+type DispatchConfig struct {
+	Workers      int    // 投递协程数
+	QueueSize    int    // 队列容量
+	Policy       string // 队列满时的策略: drop 直接丢弃 | block 阻塞等待（背压）
+	BlockTimeout int    // block 策略的最长等待时间（秒），超时后丢弃
+	DrainTimeout int    // 停止服务时等待队列中告警投递完成的最长时间（秒）
+}
+
+// ProbePoolConfig 检测工作池配置（并发上限，0 表示该类型只受全局上限约束）
+type ProbePoolConfig struct {
+	MaxConcurrency  int // 全局最大并发检测数
+	PingConcurrency int // Ping 最大并发数（特权 ICMP 套接字开销较大）
+	TCPConcurrency  int // TCP 最大并发数
+	HTTPConcurrency int // HTTP 最大并发数
 }
 
 // ProbeConfig 通用检测配置
@@ -32,6 +96,7 @@ type ProbeConfig struct {
 	Enabled          bool     `json:"enabled"`
 	Frequency        int      `json:"frequency"`
 	FailCount        int      `json:"failcount"`
+	RecoveryCount    int      `json:"recovery_count"` // 故障后连续成功多少次才判定恢复
 	Timeout          int      `json:"timeout"`
 	Retry            int      `json:"retry"`
 	RemoteUpdateFreq int      `json:"remote_update_freq"`
@@ -59,6 +124,19 @@ func Load() (*Config, error) {
 	cfg.Webhook.Method = getEnvString("WEBHOOK_METHOD", "POST")
 	cfg.Webhook.Timeout = getEnvInt("WEBHOOK_TIMEOUT", 10)
 
+	// 告警分发队列配置
+	cfg.Dispatch.Workers = getEnvInt("WEBHOOK_WORKERS", 4)
+	cfg.Dispatch.QueueSize = getEnvInt("WEBHOOK_QUEUE_SIZE", 100)
+	cfg.Dispatch.Policy = getEnvString("WEBHOOK_QUEUE_POLICY", "drop")
+	cfg.Dispatch.BlockTimeout = getEnvInt("WEBHOOK_QUEUE_BLOCK_TIMEOUT", 5)
+	cfg.Dispatch.DrainTimeout = getEnvInt("WEBHOOK_DRAIN_TIMEOUT", 10)
+
+	// 检测工作池配置
+	cfg.Probe.MaxConcurrency = getEnvInt("PROBE_MAX_CONCURRENCY", 64)
+	cfg.Probe.PingConcurrency = getEnvInt("PROBE_PING_CONCURRENCY", 16)
+	cfg.Probe.TCPConcurrency = getEnvInt("PROBE_TCP_CONCURRENCY", 0)
+	cfg.Probe.HTTPConcurrency = getEnvInt("PROBE_HTTP_CONCURRENCY", 0)
+
 	// 日志配置
 	cfg.Log.Enabled = getEnvBool("LOG_ENABLED", true)
 	cfg.Log.Level = getEnvString("LOG_LEVEL", "info")
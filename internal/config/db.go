@@ -22,7 +22,7 @@ func LoadFromDB(dbPath string) (*Config, error) {
 	// 如果没有配置，创建默认配置
 	if !hasConfig {
 		defaultCfg := storage.GetDefaultConfig()
-		if err := store.SaveConfig(defaultCfg); err != nil {
+		if _, err := store.SaveConfig(defaultCfg, "system", "默认配置"); err != nil {
 			return nil, fmt.Errorf("保存默认配置失败: %w", err)
 		}
 	}
@@ -48,6 +48,7 @@ func ApplyStorageConfig(cfg *Config, storedCfg *storage.FullConfig) {
 		Enabled:          storedCfg.Ping.Enabled,
 		Frequency:        storedCfg.Ping.Frequency,
 		FailCount:        storedCfg.Ping.FailCount,
+		RecoveryCount:    storedCfg.Ping.RecoveryCount,
 		Timeout:          storedCfg.Ping.Timeout,
 		Retry:            storedCfg.Ping.Retry,
 		RemoteUpdateFreq: storedCfg.Ping.RemoteUpdateFreq,
@@ -59,6 +60,7 @@ func ApplyStorageConfig(cfg *Config, storedCfg *storage.FullConfig) {
 		Enabled:          storedCfg.Tcp.Enabled,
 		Frequency:        storedCfg.Tcp.Frequency,
 		FailCount:        storedCfg.Tcp.FailCount,
+		RecoveryCount:    storedCfg.Tcp.RecoveryCount,
 		Timeout:          storedCfg.Tcp.Timeout,
 		Retry:            storedCfg.Tcp.Retry,
 		RemoteUpdateFreq: storedCfg.Tcp.RemoteUpdateFreq,
@@ -70,6 +72,7 @@ func ApplyStorageConfig(cfg *Config, storedCfg *storage.FullConfig) {
 		Enabled:          storedCfg.Http.Enabled,
 		Frequency:        storedCfg.Http.Frequency,
 		FailCount:        storedCfg.Http.FailCount,
+		RecoveryCount:    storedCfg.Http.RecoveryCount,
 		Timeout:          storedCfg.Http.Timeout,
 		Retry:            storedCfg.Http.Retry,
 		RemoteUpdateFreq: storedCfg.Http.RemoteUpdateFreq,
@@ -78,27 +81,58 @@ func ApplyStorageConfig(cfg *Config, storedCfg *storage.FullConfig) {
 
 	// Webhook 配置
 	cfg.Webhook = WebhookConfig{
-		URL:           storedCfg.Webhook.URL,
-		Method:        storedCfg.Webhook.Method,
-		Headers:       storedCfg.Webhook.Headers,
-		Timeout:       storedCfg.Webhook.Timeout,
-		Retry:         storedCfg.Webhook.Retry,
-		SilencePeriod: storedCfg.Webhook.SilencePeriod,
+		Type:             storedCfg.Webhook.Type,
+		URL:              storedCfg.Webhook.URL,
+		Secret:           storedCfg.Webhook.Secret,
+		Method:           storedCfg.Webhook.Method,
+		Headers:          storedCfg.Webhook.Headers,
+		Template:         storedCfg.Webhook.Template,
+		Timeout:          storedCfg.Webhook.Timeout,
+		Retry:            storedCfg.Webhook.Retry,
+		SilencePeriod:    storedCfg.Webhook.SilencePeriod,
+		ReminderInterval: storedCfg.Webhook.ReminderInterval,
+	}
+
+	// 抖动检测
+	cfg.Flap = FlapConfig(storedCfg.Flap)
+
+	// 目标标签
+	cfg.Targets = make(map[string]TargetMeta, len(storedCfg.Targets))
+	for target, meta := range storedCfg.Targets {
+		cfg.Targets[target] = TargetMeta{
+			Tags:          meta.Tags,
+			Severity:      meta.Severity,
+			RecoveryCount: meta.RecoveryCount,
+			Thresholds:    (*Thresholds)(meta.Thresholds),
+			DependsOn:     meta.DependsOn,
+			Ping:          (*PingOptions)(meta.Ping),
+		}
 	}
 }
 
-// SaveToDB 保存配置到 SQLite 数据库
-func SaveToDB(cfg *Config) error {
-	store := storage.GetStorage()
-	if store == nil {
-		return fmt.Errorf("数据库未初始化")
+// ToStorageConfig 将主配置转换为存储配置
+func ToStorageConfig(cfg *Config) *storage.FullConfig {
+	var targets map[string]storage.TargetMeta
+	if len(cfg.Targets) > 0 {
+		targets = make(map[string]storage.TargetMeta, len(cfg.Targets))
+		for target, meta := range cfg.Targets {
+			targets[target] = storage.TargetMeta{
+				Tags:          meta.Tags,
+				Severity:      meta.Severity,
+				RecoveryCount: meta.RecoveryCount,
+				Thresholds:    (*storage.Thresholds)(meta.Thresholds),
+				DependsOn:     meta.DependsOn,
+				Ping:          (*storage.PingOptions)(meta.Ping),
+			}
+		}
 	}
 
-	storedCfg := &storage.FullConfig{
+	return &storage.FullConfig{
 		Ping: storage.ProbeConfig{
 			Enabled:          cfg.Ping.Enabled,
 			Frequency:        cfg.Ping.Frequency,
 			FailCount:        cfg.Ping.FailCount,
+			RecoveryCount:    cfg.Ping.RecoveryCount,
 			Timeout:          cfg.Ping.Timeout,
 			Retry:            cfg.Ping.Retry,
 			RemoteUpdateFreq: cfg.Ping.RemoteUpdateFreq,
@@ -108,6 +142,7 @@ func SaveToDB(cfg *Config) error {
 			Enabled:          cfg.Tcp.Enabled,
 			Frequency:        cfg.Tcp.Frequency,
 			FailCount:        cfg.Tcp.FailCount,
+			RecoveryCount:    cfg.Tcp.RecoveryCount,
 			Timeout:          cfg.Tcp.Timeout,
 			Retry:            cfg.Tcp.Retry,
 			RemoteUpdateFreq: cfg.Tcp.RemoteUpdateFreq,
@@ -117,20 +152,36 @@ func SaveToDB(cfg *Config) error {
 			Enabled:          cfg.Http.Enabled,
 			Frequency:        cfg.Http.Frequency,
 			FailCount:        cfg.Http.FailCount,
+			RecoveryCount:    cfg.Http.RecoveryCount,
 			Timeout:          cfg.Http.Timeout,
 			Retry:            cfg.Http.Retry,
 			RemoteUpdateFreq: cfg.Http.RemoteUpdateFreq,
 			Domains:          cfg.Http.Domains,
 		},
 		Webhook: storage.WebhookConfig{
-			URL:           cfg.Webhook.URL,
-			Method:        cfg.Webhook.Method,
-			Headers:       cfg.Webhook.Headers,
-			Timeout:       cfg.Webhook.Timeout,
-			Retry:         cfg.Webhook.Retry,
-			SilencePeriod: cfg.Webhook.SilencePeriod,
+			Type:             cfg.Webhook.Type,
+			URL:              cfg.Webhook.URL,
+			Secret:           cfg.Webhook.Secret,
+			Method:           cfg.Webhook.Method,
+			Headers:          cfg.Webhook.Headers,
+			Template:         cfg.Webhook.Template,
+			Timeout:          cfg.Webhook.Timeout,
+			Retry:            cfg.Webhook.Retry,
+			SilencePeriod:    cfg.Webhook.SilencePeriod,
+			ReminderInterval: cfg.Webhook.ReminderInterval,
 		},
+		Targets: targets,
+		Flap:    storage.FlapConfig(cfg.Flap),
+	}
+}
+
+// SaveToDB 保存配置到 SQLite 数据库
+func SaveToDB(cfg *Config, author string) error {
+	store := storage.GetStorage()
+	if store == nil {
+		return fmt.Errorf("数据库未初始化")
 	}
 
-	return store.SaveConfig(storedCfg)
+	_, err := store.SaveConfig(ToStorageConfig(cfg), author, "")
+	return err
 }
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"dnsfailover/internal/storage"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExportVersion 导出文件格式版本
+const ExportVersion = 1
+
+// 导出/导入文件格式
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// Document 配置导出文件结构
+// 包含探针配置、监控目标、Webhook 设置及定时任务，不包含运行时数据（执行结果、用户、审计日志等）
+type Document struct {
+	Version    int                 `json:"version"`
+	ExportedAt string              `json:"exported_at,omitempty"`
+	Config     *storage.FullConfig `json:"config,omitempty"`
+	Schedules  []*ScheduleSpec     `json:"schedules"`
+}
+
+// ScheduleSpec 定时任务定义（不含执行状态）
+type ScheduleSpec struct {
+	ID            string            `json:"id,omitempty"`
+	Name          string            `json:"name"`
+	Enabled       bool              `json:"enabled"`
+	Cron          string            `json:"cron"`
+	CheckType     string            `json:"check_type"`
+	Target        string            `json:"target"`
+	Port          int               `json:"port,omitempty"`
+	Timeout       int               `json:"timeout"`
+	WebhookURL    string            `json:"webhook_url,omitempty"`
+	WebhookData   map[string]string `json:"webhook_data,omitempty"`
+	WebhookSecret string            `json:"webhook_secret,omitempty"`
+}
+
+// ExportDocument 从数据库导出当前配置与定时任务
+func ExportDocument(store *storage.Storage) (*Document, error) {
+	cfg, err := store.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := store.GetAllScheduleTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{
+		Version:   ExportVersion,
+		Config:    cfg,
+		Schedules: make([]*ScheduleSpec, 0, len(tasks)),
+	}
+	for _, task := range tasks {
+		doc.Schedules = append(doc.Schedules, specFromTask(task))
+	}
+
+	return doc, nil
+}
+
+// EncodeDocument 按指定格式序列化导出文件
+func EncodeDocument(doc *Document, format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case FormatYAML:
+		// 先转为通用结构再输出 YAML，字段名与 JSON/API 保持一致
+		var generic interface{}
+		if err := remarshal(doc, &generic); err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(generic)
+	default:
+		return nil, fmt.Errorf("不支持的格式: %s (可选 yaml/json)", format)
+	}
+}
+
+// FormatFromPath 根据文件扩展名推断格式，无法识别时默认为 YAML
+func FormatFromPath(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// specFromTask 将存储任务转换为任务定义
+func specFromTask(task *storage.ScheduleTask) *ScheduleSpec {
+	return &ScheduleSpec{
+		ID:            task.ID,
+		Name:          task.Name,
+		Enabled:       task.Enabled,
+		Cron:          task.Cron,
+		CheckType:     task.CheckType,
+		Target:        task.Target,
+		Port:          task.Port,
+		Timeout:       task.Timeout,
+		WebhookURL:    task.WebhookURL,
+		WebhookData:   task.WebhookData,
+		WebhookSecret: task.WebhookSecret,
+	}
+}
+
+// remarshal 通过 JSON 在两种结构之间转换
+func remarshal(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
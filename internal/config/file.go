@@ -0,0 +1,164 @@
+package config
+
+import (
+	"dnsfailover/internal/storage"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileSource 配置文件（GitOps 模式）
+// 文件格式与 `config export` 一致。配置完全来自文件，文件中未出现的字段使用默认值，
+// 不读取也不保存数据库中的配置与配置版本，配置不能通过 API 修改
+type FileSource struct {
+	path string
+
+	mu        sync.RWMutex
+	config    *storage.FullConfig // 文件中的配置（未出现的字段为默认值）
+	schedules interface{}         // 文件中的 schedules 部分
+	hasTasks  bool                // 文件中是否包含 schedules
+	modTime   time.Time
+	size      int64
+}
+
+// LoadFileSource 加载配置文件
+func LoadFileSource(path string) (*FileSource, error) {
+	src := &FileSource{path: path}
+	if _, err := src.Reload(); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// Path 配置文件路径
+func (f *FileSource) Path() string {
+	return f.path
+}
+
+// Reload 文件有变化时重新加载，返回是否发生了变化
+// 新内容校验失败时返回错误并保留之前的内容
+func (f *FileSource) Reload() (bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	f.mu.RLock()
+	unchanged := info.ModTime().Equal(f.modTime) && info.Size() == f.size
+	f.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return false, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	raw, err := decodeRaw(data, FormatFromPath(f.path))
+	if err != nil {
+		return false, err
+	}
+	if v, ok := raw["version"]; ok {
+		if n, ok := v.(float64); !ok || int(n) > ExportVersion {
+			return false, fmt.Errorf("不支持的文件版本: %v", v)
+		}
+	}
+
+	// 文件中的配置覆盖到默认配置上，并校验配置与定时任务
+	cfg := storage.GetDefaultConfig()
+	if rawCfg, ok := raw["config"]; ok {
+		if _, ok := rawCfg.(map[string]interface{}); !ok {
+			return false, fmt.Errorf("config 必须是对象")
+		}
+		if cfg, err = overlayConfig(cfg, rawCfg); err != nil {
+			return false, err
+		}
+	}
+	if err := NormalizeFullConfig(cfg); err != nil {
+		return false, fmt.Errorf("配置校验失败: %w", err)
+	}
+	rawTasks, hasTasks := raw["schedules"]
+	if hasTasks {
+		if _, err := planTasks(nil, rawTasks, ImportReplace); err != nil {
+			return false, err
+		}
+	}
+
+	f.mu.Lock()
+	f.config = cfg
+	f.schedules = rawTasks
+	f.hasTasks = hasTasks
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	f.mu.Unlock()
+
+	return true, nil
+}
+
+// Config 返回文件中配置的副本（未出现的字段为默认值）
+func (f *FileSource) Config() *storage.FullConfig {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var cfg storage.FullConfig
+	remarshal(f.config, &cfg)
+	return &cfg
+}
+
+// configSections 配置的顶层字段
+var configSections = []string{"flap", "http", "ping", "targets", "tcp", "webhook"}
+
+// LockedFields 由文件管理的配置字段，配置文件模式下全部配置只读
+func (f *FileSource) LockedFields() []string {
+	return append([]string{}, configSections...)
+}
+
+// SchedulesLocked 定时任务是否由文件管理
+func (f *FileSource) SchedulesLocked() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.hasTasks
+}
+
+// SyncSchedules 将文件中的定时任务同步到数据库（替换模式），返回变更列表
+// 数据库中的任务行同时保存执行状态，运行中的调度器会自动加载变更
+func (f *FileSource) SyncSchedules(store *storage.Storage, author string) ([]*TaskChange, error) {
+	f.mu.RLock()
+	rawTasks, hasTasks := f.schedules, f.hasTasks
+	f.mu.RUnlock()
+
+	if !hasTasks {
+		return nil, nil
+	}
+
+	existing, err := store.GetAllScheduleTasks()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := planTasks(existing, rawTasks, ImportReplace)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ImportPlan{Mode: ImportReplace, TaskChanges: changes}
+	if _, err := plan.Apply(store, author); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// overlayConfig 将文件中的 config 部分覆盖到给定配置上（列表整体替换）
+func overlayConfig(cfg *storage.FullConfig, rawCfg interface{}) (*storage.FullConfig, error) {
+	var base interface{}
+	if err := remarshal(cfg, &base); err != nil {
+		return nil, err
+	}
+
+	var merged storage.FullConfig
+	if err := remarshal(mergeValues(base, rawCfg, false), &merged); err != nil {
+		return nil, fmt.Errorf("解析 config 失败: %w", err)
+	}
+	return &merged, nil
+}
@@ -0,0 +1,311 @@
+package config
+
+import (
+	"dnsfailover/internal/audit"
+	"dnsfailover/internal/storage"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// ImportMode 导入模式
+type ImportMode string
+
+const (
+	// ImportMerge 合并：文件中出现的字段覆盖现有值，列表取并集，未出现的任务保留
+	ImportMerge ImportMode = "merge"
+	// ImportReplace 替换：文件中出现的部分整体替换现有内容，未出现在文件中的任务将被删除
+	ImportReplace ImportMode = "replace"
+)
+
+// 定时任务变更类型
+const (
+	TaskAdd    = "add"
+	TaskUpdate = "update"
+	TaskDelete = "delete"
+)
+
+// TaskChange 定时任务变更
+type TaskChange struct {
+	Action  string
+	Task    *storage.ScheduleTask
+	Changes []audit.Change // 仅 update 时有值
+}
+
+// ImportPlan 导入计划（可用于 dry-run 预览，或调用 Apply 写入）
+type ImportPlan struct {
+	Mode          ImportMode
+	Before        *storage.FullConfig
+	After         *storage.FullConfig
+	ConfigChanges []audit.Change
+	TaskChanges   []*TaskChange
+}
+
+// ParseImportMode 解析导入模式
+func ParseImportMode(s string) (ImportMode, error) {
+	switch ImportMode(s) {
+	case ImportMerge, ImportReplace:
+		return ImportMode(s), nil
+	default:
+		return "", fmt.Errorf("无效的导入模式: %s (可选 merge/replace)", s)
+	}
+}
+
+// PlanImport 解析导入文件并与当前数据库内容比较，生成导入计划
+// 文件中未出现的部分（config 或 schedules）在两种模式下都保持不变
+func PlanImport(store *storage.Storage, data []byte, format string, mode ImportMode) (*ImportPlan, error) {
+	raw, err := decodeRaw(data, format)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := raw["version"]; ok {
+		if n, ok := v.(float64); !ok || int(n) > ExportVersion {
+			return nil, fmt.Errorf("不支持的文件版本: %v", v)
+		}
+	}
+
+	current, err := store.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ImportPlan{Mode: mode, Before: current, After: current}
+
+	if rawCfg, ok := raw["config"]; ok {
+		after, err := buildConfig(current, rawCfg, mode)
+		if err != nil {
+			return nil, err
+		}
+		if err := NormalizeFullConfig(after); err != nil {
+			return nil, fmt.Errorf("配置校验失败: %w", err)
+		}
+		plan.After = after
+		plan.ConfigChanges = audit.Diff(current, after)
+	}
+
+	if rawTasks, ok := raw["schedules"]; ok {
+		existing, err := store.GetAllScheduleTasks()
+		if err != nil {
+			return nil, err
+		}
+		plan.TaskChanges, err = planTasks(existing, rawTasks, mode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// Empty 导入计划是否没有任何变更
+func (p *ImportPlan) Empty() bool {
+	return len(p.ConfigChanges) == 0 && len(p.TaskChanges) == 0
+}
+
+// Apply 执行导入计划。配置变更会生成新的配置版本，返回版本号（无配置变更时为 0）
+func (p *ImportPlan) Apply(store *storage.Storage, author string) (int64, error) {
+	var revision int64
+	if len(p.ConfigChanges) > 0 {
+		var err error
+		revision, err = store.SaveConfig(p.After, author, fmt.Sprintf("导入配置 (%s)", p.Mode))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, c := range p.TaskChanges {
+		var err error
+		if c.Action == TaskDelete {
+			err = store.DeleteScheduleTask(c.Task.ID)
+		} else {
+			err = store.SaveScheduleTask(c.Task)
+		}
+		if err != nil {
+			return revision, fmt.Errorf("导入定时任务 [%s] 失败: %w", c.Task.Name, err)
+		}
+	}
+
+	return revision, nil
+}
+
+// buildConfig 根据导入模式生成新配置
+func buildConfig(current *storage.FullConfig, rawCfg interface{}, mode ImportMode) (*storage.FullConfig, error) {
+	var base interface{} = map[string]interface{}{}
+	if mode == ImportMerge {
+		if err := remarshal(current, &base); err != nil {
+			return nil, err
+		}
+	}
+
+	var after storage.FullConfig
+	if err := remarshal(mergeValues(base, rawCfg, true), &after); err != nil {
+		return nil, fmt.Errorf("解析 config 失败: %w", err)
+	}
+	return &after, nil
+}
+
+// planTasks 比较导入文件与现有定时任务，生成任务变更列表
+// 文件中的任务按 id 匹配，没有 id 时按名称匹配
+func planTasks(existing []*storage.ScheduleTask, rawTasks interface{}, mode ImportMode) ([]*TaskChange, error) {
+	items, ok := rawTasks.([]interface{})
+	if !ok && rawTasks != nil {
+		return nil, fmt.Errorf("schedules 必须是列表")
+	}
+
+	byID := make(map[string]*storage.ScheduleTask)
+	byName := make(map[string]*storage.ScheduleTask)
+	for _, task := range existing {
+		byID[task.ID] = task
+		byName[task.Name] = task
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	matched := make(map[string]bool)
+	var changes []*TaskChange
+
+	for i, item := range items {
+		var spec ScheduleSpec
+		if err := remarshal(item, &spec); err != nil {
+			return nil, fmt.Errorf("解析 schedules[%d] 失败: %w", i, err)
+		}
+
+		old := byID[spec.ID]
+		if old == nil && spec.ID == "" {
+			old = byName[spec.Name]
+		}
+
+		if old == nil {
+			task := taskFromSpec(&spec, &storage.ScheduleTask{ID: spec.ID, CreatedAt: now})
+			if task.ID == "" {
+				task.ID = uuid.New().String()
+			}
+			task.UpdatedAt = now
+			if err := NormalizeScheduleTask(task); err != nil {
+				return nil, fmt.Errorf("schedules[%d] 校验失败: %w", i, err)
+			}
+			matched[task.ID] = true
+			changes = append(changes, &TaskChange{Action: TaskAdd, Task: task})
+			continue
+		}
+
+		if matched[old.ID] {
+			return nil, fmt.Errorf("schedules[%d] 与其他任务重复: %s", i, old.Name)
+		}
+		matched[old.ID] = true
+
+		// 合并模式下以现有定义为基础，仅覆盖文件中出现的字段
+		if mode == ImportMerge {
+			var base interface{}
+			if err := remarshal(specFromTask(old), &base); err != nil {
+				return nil, err
+			}
+			spec = ScheduleSpec{}
+			if err := remarshal(mergeValues(base, item, true), &spec); err != nil {
+				return nil, fmt.Errorf("解析 schedules[%d] 失败: %w", i, err)
+			}
+		}
+
+		copied := *old
+		task := taskFromSpec(&spec, &copied)
+		task.ID = old.ID
+		if err := NormalizeScheduleTask(task); err != nil {
+			return nil, fmt.Errorf("schedules[%d] 校验失败: %w", i, err)
+		}
+
+		diff := audit.Diff(specFromTask(old), specFromTask(task))
+		if len(diff) == 0 {
+			continue
+		}
+		task.UpdatedAt = now
+		changes = append(changes, &TaskChange{Action: TaskUpdate, Task: task, Changes: diff})
+	}
+
+	if mode == ImportReplace {
+		for _, task := range existing {
+			if !matched[task.ID] {
+				changes = append(changes, &TaskChange{Action: TaskDelete, Task: task})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// taskFromSpec 将任务定义写入存储任务（保留执行状态等运行时字段）
+func taskFromSpec(spec *ScheduleSpec, task *storage.ScheduleTask) *storage.ScheduleTask {
+	task.Name = spec.Name
+	task.Enabled = spec.Enabled
+	task.Cron = spec.Cron
+	task.CheckType = spec.CheckType
+	task.Target = spec.Target
+	task.Port = spec.Port
+	task.Timeout = spec.Timeout
+	task.WebhookURL = spec.WebhookURL
+	task.WebhookData = spec.WebhookData
+	task.WebhookSecret = spec.WebhookSecret
+	return task
+}
+
+// decodeRaw 将导入文件解析为通用结构（数字统一为 float64，与 JSON 一致）
+func decodeRaw(data []byte, format string) (map[string]interface{}, error) {
+	var raw map[string]interface{}
+	switch format {
+	case FormatJSON:
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("解析 JSON 失败: %w", err)
+		}
+	case FormatYAML:
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("解析 YAML 失败: %w", err)
+		}
+		if err := remarshal(doc, &raw); err != nil {
+			return nil, fmt.Errorf("解析 YAML 失败: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的格式: %s (可选 yaml/json)", format)
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("导入文件为空")
+	}
+	return raw, nil
+}
+
+// mergeValues 将 src 合并到 dst：对象逐字段合并，其他值直接覆盖
+// unionLists 为 true 时列表取并集，否则整体替换
+func mergeValues(dst, src interface{}, unionLists bool) interface{} {
+	switch s := src.(type) {
+	case map[string]interface{}:
+		d, ok := dst.(map[string]interface{})
+		if !ok {
+			return s
+		}
+		for k, v := range s {
+			d[k] = mergeValues(d[k], v, unionLists)
+		}
+		return d
+	case []interface{}:
+		d, ok := dst.([]interface{})
+		if !ok || !unionLists {
+			return s
+		}
+		seen := make(map[string]bool, len(d))
+		for _, v := range d {
+			key, _ := json.Marshal(v)
+			seen[string(key)] = true
+		}
+		for _, v := range s {
+			key, _ := json.Marshal(v)
+			if !seen[string(key)] {
+				seen[string(key)] = true
+				d = append(d, v)
+			}
+		}
+		return d
+	default:
+		return s
+	}
+}
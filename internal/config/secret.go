@@ -0,0 +1,54 @@
+package config
+
+import "dnsfailover/internal/storage"
+
+// SecretMask 接口返回中替代密钥等敏感字段的占位符
+const SecretMask = "******"
+
+// MaskSecret 敏感字段非空时替换为占位符
+func MaskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return SecretMask
+}
+
+// KeepSecret 提交的敏感字段为空或为占位符时沿用已保存的值
+func KeepSecret(submitted, stored string) string {
+	if submitted == "" || submitted == SecretMask {
+		return stored
+	}
+	return submitted
+}
+
+// RedactFullConfig 返回隐藏 Webhook 密钥的配置副本，用于接口返回与审计日志
+func RedactFullConfig(cfg *storage.FullConfig) *storage.FullConfig {
+	if cfg == nil {
+		return nil
+	}
+	redacted := *cfg
+	redacted.Webhook.Secret = MaskSecret(cfg.Webhook.Secret)
+	return &redacted
+}
+
+// RedactDocument 返回隐藏全局与定时任务 Webhook 密钥的导出文件副本，用于审计日志
+func RedactDocument(doc *Document) *Document {
+	if doc == nil {
+		return nil
+	}
+	redacted := *doc
+	redacted.Config = RedactFullConfig(doc.Config)
+	if doc.Schedules != nil {
+		redacted.Schedules = make([]*ScheduleSpec, 0, len(doc.Schedules))
+		for _, spec := range doc.Schedules {
+			if spec == nil {
+				redacted.Schedules = append(redacted.Schedules, nil)
+				continue
+			}
+			masked := *spec
+			masked.WebhookSecret = MaskSecret(spec.WebhookSecret)
+			redacted.Schedules = append(redacted.Schedules, &masked)
+		}
+	}
+	return &redacted
+}
@@ -0,0 +1,475 @@
+package config
+
+import (
+	"dnsfailover/internal/probe"
+	"dnsfailover/internal/storage"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// 取值范围（与 webhook 包中的告警字段一致）
+var (
+	severityLevels = []string{"critical", "warning", "info"}
+	probeTypes     = []string{"ping", "tcp", "http"}
+	alertTypes     = []string{"down", "recovery", "flapping", "stabilized", "degraded"}
+	webhookTypes   = []string{"webhook", "dingtalk", "feishu", "slack", "telegram", "wecom"}
+	channelTypes   = []string{"webhook", "dingtalk", "feishu", "slack", "telegram", "wecom", "email"}
+	emailTLSModes  = []string{"starttls", "tls", "none"}
+)
+
+// NormalizeFullConfig 校验存储配置并填充默认值
+// Web 面板保存、配置导入等所有写入路径共用同一套规则
+func NormalizeFullConfig(cfg *storage.FullConfig) error {
+	// 至少启用一种探针
+	if !cfg.Ping.Enabled && !cfg.Tcp.Enabled && !cfg.Http.Enabled {
+		return fmt.Errorf("至少需要启用一种探针 (ping/tcp/http)")
+	}
+
+	setProbeDefaults(&cfg.Ping)
+	setProbeDefaults(&cfg.Tcp)
+	setProbeDefaults(&cfg.Http)
+	if cfg.Webhook.Type == "" {
+		cfg.Webhook.Type = "webhook"
+	}
+	if err := checkValues("Webhook 类型", []string{cfg.Webhook.Type}, webhookTypes); err != nil {
+		return err
+	}
+	if cfg.Webhook.Method == "" {
+		cfg.Webhook.Method = "POST"
+	}
+	if cfg.Webhook.Timeout == 0 {
+		cfg.Webhook.Timeout = 10
+	}
+	if cfg.Webhook.SilencePeriod == 0 {
+		cfg.Webhook.SilencePeriod = 60 // 默认 60 秒静默期
+	}
+	if cfg.Webhook.ReminderInterval < 0 {
+		return fmt.Errorf("提醒间隔不能为负数")
+	}
+
+	if err := normalizeFlap(&cfg.Flap); err != nil {
+		return err
+	}
+
+	for target, meta := range cfg.Targets {
+		meta.Tags = normalizeList(meta.Tags)
+		meta.Severity = strings.ToLower(strings.TrimSpace(meta.Severity))
+		if meta.Severity != "" {
+			if err := checkValues("告警级别", []string{meta.Severity}, severityLevels); err != nil {
+				return fmt.Errorf("目标 %s: %w", target, err)
+			}
+		}
+		if meta.RecoveryCount < 0 {
+			return fmt.Errorf("目标 %s: 恢复阈值不能为负数", target)
+		}
+		if err := normalizeThresholds(&meta.Thresholds); err != nil {
+			return fmt.Errorf("目标 %s: %w", target, err)
+		}
+		meta.DependsOn = normalizeList(meta.DependsOn)
+		if err := normalizePingOptions(&meta.Ping, cfg.Ping.Timeout); err != nil {
+			return fmt.Errorf("目标 %s: %w", target, err)
+		}
+		if len(meta.Tags) == 0 && meta.Severity == "" && meta.RecoveryCount == 0 && meta.Thresholds == nil &&
+			len(meta.DependsOn) == 0 && meta.Ping == nil {
+			delete(cfg.Targets, target)
+			continue
+		}
+		cfg.Targets[target] = meta
+	}
+
+	return checkDependencies(cfg)
+}
+
+// checkDependencies 校验上游依赖：上游必须是已配置的检测目标，且不能存在循环依赖
+func checkDependencies(cfg *storage.FullConfig) error {
+	known := make(map[string]bool)
+	for _, probe := range []storage.ProbeConfig{cfg.Ping, cfg.Tcp, cfg.Http} {
+		for _, domain := range probe.Domains {
+			known[domain] = true
+		}
+	}
+
+	for target, meta := range cfg.Targets {
+		for _, parent := range meta.DependsOn {
+			if parent == target {
+				return fmt.Errorf("目标 %s: 不能依赖自身", target)
+			}
+			if !known[parent] {
+				return fmt.Errorf("目标 %s: 上游依赖 %s 不是已配置的检测目标", target, parent)
+			}
+		}
+	}
+
+	// 深度优先检查循环依赖：1 表示检查中，2 表示已检查
+	visited := make(map[string]int)
+	var visit func(target string, path []string) error
+	visit = func(target string, path []string) error {
+		switch visited[target] {
+		case 1:
+			return fmt.Errorf("存在循环依赖: %s", strings.Join(append(path, target), " → "))
+		case 2:
+			return nil
+		}
+		visited[target] = 1
+		for _, parent := range cfg.Targets[target].DependsOn {
+			if err := visit(parent, append(path, target)); err != nil {
+				return err
+			}
+		}
+		visited[target] = 2
+		return nil
+	}
+	for target := range cfg.Targets {
+		if err := visit(target, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setProbeDefaults 设置探针默认值
+func setProbeDefaults(cfg *storage.ProbeConfig) {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5
+	}
+	if cfg.Retry == 0 {
+		cfg.Retry = 3
+	}
+	if cfg.Frequency == 0 {
+		cfg.Frequency = 30
+	}
+	if cfg.FailCount == 0 {
+		cfg.FailCount = 3
+	}
+	if cfg.RecoveryCount <= 0 {
+		cfg.RecoveryCount = 1
+	}
+}
+
+// normalizeFlap 校验抖动检测配置并填充默认值
+func normalizeFlap(cfg *storage.FlapConfig) error {
+	if cfg.Window == 0 {
+		cfg.Window = 20
+	}
+	if cfg.HighThreshold == 0 {
+		cfg.HighThreshold = 50
+	}
+	if cfg.LowThreshold == 0 {
+		cfg.LowThreshold = 25
+	}
+	if cfg.Window < 3 || cfg.Window > 100 {
+		return fmt.Errorf("抖动检测窗口需在 3 到 100 次之间")
+	}
+	if cfg.HighThreshold > 100 || cfg.LowThreshold < 0 || cfg.LowThreshold >= cfg.HighThreshold {
+		return fmt.Errorf("抖动检测阈值需满足 0 <= 退出阈值 < 进入阈值 <= 100")
+	}
+	return nil
+}
+
+// normalizeThresholds 校验性能降级阈值，全部为 0 时置空
+func normalizeThresholds(t **storage.Thresholds) error {
+	th := *t
+	if th == nil {
+		return nil
+	}
+	if *th == (storage.Thresholds{}) {
+		*t = nil
+		return nil
+	}
+
+	pairs := []struct {
+		name              string
+		warning, critical int
+	}{
+		{"延迟", th.LatencyWarning, th.LatencyCritical},
+		{"丢包率", th.LossWarning, th.LossCritical},
+		{"抖动", th.JitterWarning, th.JitterCritical},
+	}
+	for _, p := range pairs {
+		if p.warning < 0 || p.critical < 0 {
+			return fmt.Errorf("%s阈值不能为负数", p.name)
+		}
+		if p.warning > 0 && p.critical > 0 && p.warning > p.critical {
+			return fmt.Errorf("%s的 warning 阈值不能大于 critical 阈值", p.name)
+		}
+	}
+	if th.LossWarning > 100 || th.LossCritical > 100 {
+		return fmt.Errorf("丢包率阈值不能超过 100")
+	}
+	return nil
+}
+
+// normalizePingOptions 校验 Ping 检测参数，全部为 0 时置空
+// timeout 为 Ping 探针超时（秒），发完全部包所需时间不能超过超时
+func normalizePingOptions(o **storage.PingOptions, timeout int) error {
+	opts := *o
+	if opts == nil {
+		return nil
+	}
+	if *opts == (storage.PingOptions{}) {
+		*o = nil
+		return nil
+	}
+
+	if opts.Count < 0 || opts.Count > 100 {
+		return fmt.Errorf("Ping 发包数需在 1-100 之间")
+	}
+	if opts.Interval < 0 || (opts.Interval > 0 && opts.Interval < 10) {
+		return fmt.Errorf("Ping 发包间隔不能小于 10 毫秒")
+	}
+	if opts.Size < 0 || (opts.Size > 0 && opts.Size < 24) || opts.Size > 65500 {
+		return fmt.Errorf("Ping 负载大小需在 24-65500 字节之间")
+	}
+	if opts.TTL < 0 || opts.TTL > 255 {
+		return fmt.Errorf("Ping TTL 需在 1-255 之间")
+	}
+	if opts.LossThreshold < 0 || opts.LossThreshold > 100 {
+		return fmt.Errorf("Ping 丢包率阈值需在 1-100 之间")
+	}
+
+	// 未设置的字段按默认值计算
+	count, interval := probe.DefaultPingOptions().Count, probe.DefaultPingOptions().Interval
+	if opts.Count > 0 {
+		count = opts.Count
+	}
+	if opts.Interval > 0 {
+		interval = time.Duration(opts.Interval) * time.Millisecond
+	}
+	if total := time.Duration(count) * interval; total > time.Duration(timeout)*time.Second {
+		return fmt.Errorf("Ping 发包数 × 发包间隔 (%d × %v = %v) 超过 Ping 超时 %d 秒，检测将总是超时", count, interval, total, timeout)
+	}
+	return nil
+}
+
+// NormalizeScheduleTask 校验定时任务必填字段并填充默认值
+func NormalizeScheduleTask(task *storage.ScheduleTask) error {
+	if task.Name == "" {
+		return fmt.Errorf("任务名称不能为空")
+	}
+	if task.Cron == "" {
+		return fmt.Errorf("Cron 表达式不能为空")
+	}
+	if task.Target == "" {
+		return fmt.Errorf("检测目标不能为空")
+	}
+	if task.CheckType == "" {
+		task.CheckType = "ping"
+	}
+	if task.Timeout == 0 {
+		task.Timeout = 5
+	}
+	return nil
+}
+
+// NormalizeChannel 校验通知通道并填充默认值
+func NormalizeChannel(ch *storage.NotifyChannel) error {
+	ch.Name = strings.TrimSpace(ch.Name)
+	if ch.Name == "" {
+		return fmt.Errorf("通道名称不能为空")
+	}
+	if ch.Type == "" {
+		ch.Type = "webhook"
+	}
+	if err := checkValues("通道类型", []string{ch.Type}, channelTypes); err != nil {
+		return err
+	}
+	if ch.Type == "email" {
+		if err := normalizeEmail(ch.Email); err != nil {
+			return err
+		}
+	} else {
+		if ch.URL == "" {
+			return fmt.Errorf("通道 URL 不能为空")
+		}
+		ch.Email = nil
+	}
+	if ch.Method == "" {
+		ch.Method = "POST"
+	}
+	if ch.Timeout == 0 {
+		ch.Timeout = 10
+	}
+	return nil
+}
+
+// normalizeEmail 校验邮件通道的 SMTP 配置，端口按加密方式取默认值
+func normalizeEmail(cfg *storage.EmailConfig) error {
+	if cfg == nil || strings.TrimSpace(cfg.Host) == "" {
+		return fmt.Errorf("SMTP 服务器不能为空")
+	}
+	cfg.Host = strings.TrimSpace(cfg.Host)
+	cfg.From = strings.TrimSpace(cfg.From)
+	if cfg.From == "" {
+		return fmt.Errorf("发件人不能为空")
+	}
+	cfg.To = normalizeList(cfg.To)
+	if len(cfg.To) == 0 {
+		return fmt.Errorf("至少需要一个收件人")
+	}
+
+	if cfg.TLS == "" {
+		cfg.TLS = "starttls"
+	}
+	if err := checkValues("加密方式", []string{cfg.TLS}, emailTLSModes); err != nil {
+		return err
+	}
+	if cfg.Port == 0 {
+		switch cfg.TLS {
+		case "tls":
+			cfg.Port = 465
+		case "none":
+			cfg.Port = 25
+		default:
+			cfg.Port = 587
+		}
+	}
+	return nil
+}
+
+// NormalizeRouteRule 校验路由规则的匹配条件
+func NormalizeRouteRule(rule *storage.RouteRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return fmt.Errorf("规则名称不能为空")
+	}
+
+	rule.Tags = normalizeList(rule.Tags)
+	rule.ProbeTypes = normalizeList(rule.ProbeTypes)
+	rule.AlertTypes = normalizeList(rule.AlertTypes)
+	rule.Severities = normalizeList(rule.Severities)
+	rule.Channels = normalizeList(rule.Channels)
+	rule.Escalation = strings.TrimSpace(rule.Escalation)
+
+	if len(rule.Channels) == 0 {
+		return fmt.Errorf("至少需要选择一个通知通道")
+	}
+	if err := checkValues("探针类型", rule.ProbeTypes, probeTypes); err != nil {
+		return err
+	}
+	if err := checkValues("告警类型", rule.AlertTypes, alertTypes); err != nil {
+		return err
+	}
+	return checkValues("告警级别", rule.Severities, severityLevels)
+}
+
+// NormalizeEscalationPolicy 校验升级策略：步骤按触发时间升序，每步至少一个通道
+func NormalizeEscalationPolicy(policy *storage.EscalationPolicy) error {
+	policy.Name = strings.TrimSpace(policy.Name)
+	if policy.Name == "" {
+		return fmt.Errorf("策略名称不能为空")
+	}
+	if len(policy.Steps) == 0 {
+		return fmt.Errorf("至少需要一个升级步骤")
+	}
+
+	for i := range policy.Steps {
+		step := &policy.Steps[i]
+		step.Channels = normalizeList(step.Channels)
+		if len(step.Channels) == 0 {
+			return fmt.Errorf("步骤 #%d 至少需要选择一个通知通道", i+1)
+		}
+		if step.After < 0 {
+			return fmt.Errorf("步骤 #%d 的触发时间不能为负数", i+1)
+		}
+		if i > 0 && step.After < policy.Steps[i-1].After {
+			return fmt.Errorf("步骤 #%d 的触发时间不能早于上一步骤", i+1)
+		}
+	}
+	return nil
+}
+
+// maintenanceTimeLayouts 维护窗口起止时间可接受的输入格式（统一保存为第一种）
+var maintenanceTimeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// NormalizeMaintenanceWindow 校验维护窗口
+// 一次性窗口需要开始与结束时间，周期窗口需要 Cron 表达式与持续分钟数，二者只能选其一
+func NormalizeMaintenanceWindow(mw *storage.MaintenanceWindow) error {
+	mw.Name = strings.TrimSpace(mw.Name)
+	if mw.Name == "" {
+		return fmt.Errorf("维护窗口名称不能为空")
+	}
+	mw.Targets = normalizeList(mw.Targets)
+	mw.Tags = normalizeList(mw.Tags)
+	if len(mw.Targets) == 0 && len(mw.Tags) == 0 {
+		return fmt.Errorf("至少需要指定一个目标或标签")
+	}
+
+	mw.Cron = strings.TrimSpace(mw.Cron)
+	if mw.Cron != "" {
+		if mw.StartAt != "" || mw.EndAt != "" {
+			return fmt.Errorf("周期窗口不能同时设置开始与结束时间")
+		}
+		if _, err := cron.ParseStandard(mw.Cron); err != nil {
+			return fmt.Errorf("无效的 Cron 表达式: %w", err)
+		}
+		if mw.Duration <= 0 {
+			return fmt.Errorf("周期窗口的持续时间必须大于 0 分钟")
+		}
+		return nil
+	}
+
+	mw.Duration = 0
+	start, err := parseMaintenanceTime("开始时间", mw.StartAt)
+	if err != nil {
+		return err
+	}
+	end, err := parseMaintenanceTime("结束时间", mw.EndAt)
+	if err != nil {
+		return err
+	}
+	if !end.After(start) {
+		return fmt.Errorf("结束时间必须晚于开始时间")
+	}
+	mw.StartAt = start.Format(maintenanceTimeLayouts[0])
+	mw.EndAt = end.Format(maintenanceTimeLayouts[0])
+	return nil
+}
+
+// parseMaintenanceTime 解析维护窗口的本地时间
+func parseMaintenanceTime(field, value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("一次性窗口的%s不能为空（或改用 Cron 设置周期窗口）", field)
+	}
+	for _, layout := range maintenanceTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无效的%s: %s（格式: 2006-01-02 15:04）", field, value)
+}
+
+// normalizeList 去除空白与重复项
+func normalizeList(list []string) []string {
+	result := []string{}
+	seen := make(map[string]bool)
+	for _, v := range list {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
+
+// checkValues 检查取值是否都在允许范围内
+func checkValues(field string, values, allowed []string) error {
+	for _, v := range values {
+		ok := false
+		for _, a := range allowed {
+			if v == a {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("无效的%s: %s (可选: %s)", field, v, strings.Join(allowed, "/"))
+		}
+	}
+	return nil
+}
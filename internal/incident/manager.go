@@ -0,0 +1,280 @@
+package incident
+
+import (
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/maintenance"
+	"dnsfailover/internal/silence"
+	"dnsfailover/internal/storage"
+	"dnsfailover/internal/webhook"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// escalationInterval 检查升级步骤的间隔
+const escalationInterval = 15 * time.Second
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// Manager 故障事件管理器
+// 首次故障告警时创建事件，目标恢复时关闭；事件未确认期间按升级策略逐步通知更多通道
+type Manager struct {
+	client   *webhook.Client
+	open     map[string]int64 // 目标+探针类型 -> 未恢复的事件 ID
+	stopChan chan struct{}
+	running  bool
+	mu       sync.Mutex
+}
+
+// NewManager 创建故障事件管理器
+func NewManager(client *webhook.Client) *Manager {
+	return &Manager{
+		client: client,
+		open:   make(map[string]int64),
+	}
+}
+
+// Start 加载未恢复的事件并启动升级循环
+func (m *Manager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return
+	}
+
+	if store := storage.GetStorage(); store != nil {
+		incidents, err := store.QueryIncidents(storage.IncidentOpen+","+storage.IncidentAcknowledged, 1000)
+		if err != nil {
+			logger.Warnf("[INCIDENT] 加载未恢复事件失败: %v", err)
+		}
+		for _, inc := range incidents {
+			m.open[incidentKey(inc.Target, inc.ProbeType)] = inc.ID
+		}
+	}
+
+	m.stopChan = make(chan struct{})
+	m.running = true
+	go m.escalationLoop(m.stopChan)
+}
+
+// Stop 停止升级循环
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running {
+		return
+	}
+	close(m.stopChan)
+	m.running = false
+}
+
+// Open 为故障告警创建事件，返回事件 ID
+// 同一目标与探针类型已有未恢复的事件时直接返回该事件（静默期后重复告警不会新建事件）
+func (m *Manager) Open(alert *webhook.Alert) int64 {
+	store := storage.GetStorage()
+	if store == nil {
+		return 0
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := incidentKey(alert.Target, alert.ProbeType)
+	if id, exists := m.open[key]; exists {
+		return id
+	}
+
+	now := time.Now().Format(timeLayout)
+	inc := &storage.Incident{
+		Target:    alert.Target,
+		ProbeType: alert.ProbeType,
+		Severity:  string(alert.Severity),
+		Tags:      alert.Tags,
+		Error:     alert.Error,
+		Status:    storage.IncidentOpen,
+		PolicyID:  webhook.EscalationPolicyFor(alert),
+		StartedAt: now,
+	}
+	if _, err := store.CreateIncident(inc); err != nil {
+		logger.Errorf("[INCIDENT] %v", err)
+		return 0
+	}
+	m.open[key] = inc.ID
+
+	message := alert.Error
+	if inc.PolicyID != "" {
+		if policy, _ := store.GetEscalationPolicy(inc.PolicyID); policy != nil {
+			message = fmt.Sprintf("%s（升级策略: %s）", alert.Error, policy.Name)
+		}
+	}
+	addEvent(store, inc.ID, storage.IncidentEventOpened, 0, nil, "", message)
+	logger.Infof("[INCIDENT] 创建事件 #%d: %s [%s]", inc.ID, inc.Target, inc.ProbeType)
+	return inc.ID
+}
+
+// Resolve 目标恢复时关闭事件，返回事件 ID（没有未恢复的事件时返回 0）
+func (m *Manager) Resolve(target, probeType string) int64 {
+	m.mu.Lock()
+	key := incidentKey(target, probeType)
+	id, exists := m.open[key]
+	delete(m.open, key)
+	m.mu.Unlock()
+
+	if !exists {
+		return 0
+	}
+
+	store := storage.GetStorage()
+	if store == nil {
+		return id
+	}
+
+	inc, err := store.GetIncident(id)
+	if err != nil || inc == nil || inc.Status == storage.IncidentResolved {
+		return id
+	}
+
+	inc.Status = storage.IncidentResolved
+	inc.ResolvedAt = time.Now().Format(timeLayout)
+	if err := store.UpdateIncident(inc); err != nil {
+		logger.Errorf("[INCIDENT] %v", err)
+		return id
+	}
+	addEvent(store, inc.ID, storage.IncidentEventResolved, 0, nil, "", "目标已恢复")
+	logger.Infof("[INCIDENT] 事件 #%d 已恢复: %s [%s]", inc.ID, inc.Target, inc.ProbeType)
+	return id
+}
+
+// Acknowledge 确认事件，停止后续升级
+func Acknowledge(id int64, actor, comment string) (*storage.Incident, error) {
+	store := storage.GetStorage()
+	if store == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	inc, err := store.GetIncident(id)
+	if err != nil {
+		return nil, err
+	}
+	if inc == nil {
+		return nil, fmt.Errorf("事件不存在: %d", id)
+	}
+	if inc.Status != storage.IncidentOpen {
+		return nil, fmt.Errorf("事件当前状态为 %s，无法确认", inc.Status)
+	}
+
+	inc.Status = storage.IncidentAcknowledged
+	inc.AcknowledgedAt = time.Now().Format(timeLayout)
+	inc.AcknowledgedBy = actor
+	if err := store.UpdateIncident(inc); err != nil {
+		return nil, err
+	}
+	addEvent(store, inc.ID, storage.IncidentEventAcknowledged, 0, nil, actor, comment)
+	logger.Infof("[INCIDENT] 事件 #%d 已被 %s 确认", inc.ID, actor)
+	return inc, nil
+}
+
+// escalationLoop 定期检查未确认的事件是否需要升级
+func (m *Manager) escalationLoop(stop chan struct{}) {
+	ticker := time.NewTicker(escalationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.escalate()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// escalate 对到期的事件执行下一个升级步骤
+func (m *Manager) escalate() {
+	store := storage.GetStorage()
+	if store == nil {
+		return
+	}
+
+	incidents, err := store.QueryIncidents(storage.IncidentOpen, 1000)
+	if err != nil {
+		logger.Warnf("[INCIDENT] 查询未确认事件失败: %v", err)
+		return
+	}
+
+	for _, inc := range incidents {
+		// 维护窗口或静音期间暂停升级
+		if inc.PolicyID == "" || maintenance.ActiveFor(inc.Target, inc.Tags) != nil || silence.Active(inc.Target) != nil {
+			continue
+		}
+		policy, err := store.GetEscalationPolicy(inc.PolicyID)
+		if err != nil || policy == nil || !policy.Enabled || inc.Step >= len(policy.Steps) {
+			continue
+		}
+
+		started, err := time.ParseInLocation(timeLayout, inc.StartedAt, time.Local)
+		if err != nil {
+			continue
+		}
+		elapsed := time.Since(started)
+		step := policy.Steps[inc.Step]
+		if elapsed < time.Duration(step.After)*time.Minute {
+			continue
+		}
+
+		m.runStep(store, inc, policy, step, elapsed)
+	}
+}
+
+// runStep 执行一个升级步骤并记录到事件时间线
+func (m *Manager) runStep(store *storage.Storage, inc *storage.Incident, policy *storage.EscalationPolicy, step storage.EscalationStep, elapsed time.Duration) {
+	inc.Step++
+
+	alert := &webhook.Alert{
+		Type:       webhook.AlertTypeDown,
+		Severity:   webhook.Severity(inc.Severity),
+		ProbeType:  inc.ProbeType,
+		Target:     inc.Target,
+		Tags:       inc.Tags,
+		Error:      inc.Error,
+		Duration:   int64(elapsed.Seconds()),
+		Incident:   inc.ID,
+		Escalation: inc.Step,
+	}
+
+	names, err := m.client.SendToChannels(alert, step.Channels)
+	message := fmt.Sprintf("策略 %s 步骤 #%d（%d 分钟未确认）", policy.Name, inc.Step, step.After)
+	if err != nil {
+		message += ": " + err.Error()
+	}
+
+	if err := store.UpdateIncident(inc); err != nil {
+		logger.Errorf("[INCIDENT] %v", err)
+		return
+	}
+	addEvent(store, inc.ID, storage.IncidentEventEscalated, inc.Step, names, "", message)
+	logger.Warnf("[INCIDENT] 事件 #%d 升级: %s", inc.ID, message)
+}
+
+// addEvent 记录事件时间线
+func addEvent(store *storage.Storage, incidentID int64, eventType string, step int, channels []string, actor, message string) {
+	err := store.AddIncidentEvent(&storage.IncidentEvent{
+		IncidentID: incidentID,
+		Type:       eventType,
+		Step:       step,
+		Channels:   channels,
+		Actor:      actor,
+		Message:    message,
+		CreatedAt:  time.Now().Format(timeLayout),
+	})
+	if err != nil {
+		logger.Errorf("[INCIDENT] %v", err)
+	}
+}
+
+// incidentKey 事件索引键
+func incidentKey(target, probeType string) string {
+	return target + "|" + probeType
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"io"
 	"os"
 
 	"github.com/sirupsen/logrus"
@@ -8,6 +9,10 @@ import (
 
 var Log *logrus.Logger
 
+// ConsoleOutput 控制台日志输出目标，需在初始化前设置
+// 命令行将数据输出到标准输出时（如导出配置），可改为 os.Stderr 避免混入日志
+var ConsoleOutput io.Writer = os.Stdout
+
 // MemoryHook 内存日志钩子
 type MemoryHook struct {
 	buffer *LogBuffer
@@ -46,7 +51,7 @@ func Init(level, logPath string, maxDays int) error {
 	})
 
 	// 仅输出到控制台
-	Log.SetOutput(os.Stdout)
+	Log.SetOutput(ConsoleOutput)
 
 	// 初始化内存缓冲区（保留最近1000条日志）
 	InitBuffer(1000)
@@ -76,7 +81,7 @@ func InitConsoleOnly(level string) {
 	})
 
 	// 仅输出到控制台
-	Log.SetOutput(os.Stdout)
+	Log.SetOutput(ConsoleOutput)
 }
 
 // Debug 调试日志
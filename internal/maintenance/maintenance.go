@@ -0,0 +1,123 @@
+// Package maintenance 计划维护窗口
+//
+// 维护窗口内探针照常执行并记录结果，但不发送告警、不升级事件、不触发定时任务的 Webhook。
+package maintenance
+
+import (
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/storage"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// TimeLayout 一次性窗口起止时间格式
+const TimeLayout = "2006-01-02 15:04:05"
+
+// refreshInterval 重新加载维护窗口的间隔（用于感知 CLI 等其他进程的修改）
+const refreshInterval = 5 * time.Second
+
+// cache 已加载的维护窗口
+var cache struct {
+	mu       sync.Mutex
+	windows  []*storage.MaintenanceWindow
+	loadedAt time.Time
+}
+
+// Invalidate 使缓存失效，下次查询时重新加载
+func Invalidate() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.loadedAt = time.Time{}
+}
+
+// ActiveFor 返回目标当前所处的维护窗口，不在维护中时返回 nil
+func ActiveFor(target string, tags []string) *storage.MaintenanceWindow {
+	now := time.Now()
+	for _, mw := range loadWindows(now) {
+		if Covers(mw, target, tags) && IsActive(mw, now) {
+			return mw
+		}
+	}
+	return nil
+}
+
+// Covers 判断维护窗口是否作用于目标（目标名相同，或标签命中任一）
+func Covers(mw *storage.MaintenanceWindow, target string, tags []string) bool {
+	for _, t := range mw.Targets {
+		if strings.EqualFold(t, target) {
+			return true
+		}
+	}
+	for _, want := range mw.Tags {
+		for _, tag := range tags {
+			if strings.EqualFold(want, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsActive 判断维护窗口在指定时间是否生效
+func IsActive(mw *storage.MaintenanceWindow, now time.Time) bool {
+	if mw.Cron != "" {
+		sched, err := cron.ParseStandard(mw.Cron)
+		if err != nil {
+			return false
+		}
+		// 最近一次开始时间落在 (now-duration, now] 内即处于窗口中
+		duration := time.Duration(mw.Duration) * time.Minute
+		return !sched.Next(now.Add(-duration)).After(now)
+	}
+
+	start, err1 := time.ParseInLocation(TimeLayout, mw.StartAt, time.Local)
+	end, err2 := time.ParseInLocation(TimeLayout, mw.EndAt, time.Local)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return !now.Before(start) && now.Before(end)
+}
+
+// NextStart 返回维护窗口下一次开始时间（已结束的一次性窗口返回零值）
+func NextStart(mw *storage.MaintenanceWindow, now time.Time) time.Time {
+	if mw.Cron != "" {
+		sched, err := cron.ParseStandard(mw.Cron)
+		if err != nil {
+			return time.Time{}
+		}
+		return sched.Next(now)
+	}
+
+	start, err := time.ParseInLocation(TimeLayout, mw.StartAt, time.Local)
+	if err != nil || !start.After(now) {
+		return time.Time{}
+	}
+	return start
+}
+
+// loadWindows 返回缓存的维护窗口，过期时从数据库重新加载
+func loadWindows(now time.Time) []*storage.MaintenanceWindow {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if now.Sub(cache.loadedAt) < refreshInterval {
+		return cache.windows
+	}
+
+	store := storage.GetStorage()
+	if store == nil {
+		return nil
+	}
+	windows, err := store.GetAllMaintenanceWindows()
+	if err != nil {
+		logger.Warnf("[MAINT] 加载维护窗口失败: %v", err)
+		return cache.windows
+	}
+
+	cache.windows = windows
+	cache.loadedAt = now
+	return windows
+}
@@ -0,0 +1,117 @@
+package monitor
+
+import (
+	"dnsfailover/internal/config"
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/probe"
+	"dnsfailover/internal/webhook"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 性能降级级别
+const (
+	DegradedWarning  = "warning"
+	DegradedCritical = "critical"
+)
+
+// RecordPerformance 记录检测成功时的延迟，返回抖动
+// 探针未给出抖动（TCP/HTTP）时取与上次延迟之差的绝对值
+func (sm *StateManager) RecordPerformance(domain string, latency, jitter time.Duration, loss float64) time.Duration {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	state, exists := sm.states[domain]
+	if !exists {
+		return jitter
+	}
+
+	if jitter == 0 && state.LastLatency > 0 {
+		jitter = latency - state.LastLatency
+		if jitter < 0 {
+			jitter = -jitter
+		}
+	}
+	state.LastLatency = latency
+	state.Jitter = jitter
+	state.PacketLoss = loss
+	return jitter
+}
+
+// SetDegraded 设置性能降级级别与原因，返回之前的级别
+func (sm *StateManager) SetDegraded(domain, level, reason string) string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	state, exists := sm.states[domain]
+	if !exists {
+		return ""
+	}
+	previous := state.Degraded
+	state.Degraded = level
+	state.DegradedReason = reason
+	return previous
+}
+
+// evaluateThresholds 按阈值判断降级级别，返回级别（未降级时为空）与超出阈值的说明
+func evaluateThresholds(th *config.Thresholds, latency time.Duration, loss float64, jitter time.Duration) (string, string) {
+	if th == nil {
+		return "", ""
+	}
+
+	level := ""
+	var reasons []string
+	check := func(name string, value, warning, critical int, unit string) {
+		switch {
+		case critical > 0 && value >= critical:
+			level = DegradedCritical
+			reasons = append(reasons, fmt.Sprintf("%s %d%s ≥ %d%s", name, value, unit, critical, unit))
+		case warning > 0 && value >= warning:
+			if level == "" {
+				level = DegradedWarning
+			}
+			reasons = append(reasons, fmt.Sprintf("%s %d%s ≥ %d%s", name, value, unit, warning, unit))
+		}
+	}
+	check("延迟", int(latency.Milliseconds()), th.LatencyWarning, th.LatencyCritical, "ms")
+	check("丢包率", int(loss), th.LossWarning, th.LossCritical, "%")
+	check("抖动", int(jitter.Milliseconds()), th.JitterWarning, th.JitterCritical, "ms")
+
+	return level, strings.Join(reasons, "，")
+}
+
+// checkPerformance 按目标的性能阈值判断是否降级，降级级别变化时发送降级告警
+func (s *Scheduler) checkPerformance(target string, probeType probe.ProbeType, result *probe.Result) {
+	typeTag := fmt.Sprintf("%-4s", probeType)
+	jitter := s.stateManager.RecordPerformance(target, result.Latency, result.Jitter, result.PacketLoss)
+
+	s.configMu.RLock()
+	thresholds := s.cfg.Targets[target].Thresholds
+	s.configMu.RUnlock()
+
+	level, reason := evaluateThresholds(thresholds, result.Latency, result.PacketLoss, jitter)
+	if previous := s.stateManager.SetDegraded(target, level, reason); previous == level {
+		return
+	}
+
+	alert := s.newAlert(webhook.AlertTypeDegraded, probeType, target)
+	alert.LatencyMs = result.Latency.Milliseconds()
+	alert.PacketLoss = result.PacketLoss
+	alert.JitterMs = jitter.Milliseconds()
+	if level == "" {
+		alert.State = TargetStateUp
+		logger.Infof("[%s] ✓ %s 性能已恢复正常", typeTag, target)
+	} else {
+		alert.State = TargetStateDegraded
+		alert.Severity = webhook.Severity(level)
+		alert.Error = reason
+		logger.Warnf("[%s] ▼ %s 性能降级 (%s): %s", typeTag, target, level, reason)
+	}
+
+	if reason := s.suppression(target); reason != "" {
+		logger.Infof("[%s] ⏸ %s %s，不发送降级告警", typeTag, target, reason)
+		return
+	}
+	s.dispatcher.Dispatch(alert)
+}
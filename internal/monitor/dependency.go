@@ -0,0 +1,66 @@
+package monitor
+
+import (
+	"time"
+)
+
+// IsDown 判断目标是否已判定故障
+func (sm *StateManager) IsDown(domain string) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if state, exists := sm.states[domain]; exists {
+		return state.IsDown
+	}
+	return false
+}
+
+// MarkDownSuppressed 标记为故障状态但不记为已告警（故障被上游抑制）
+// 返回抑制的上游是否变化，用于只在首次抑制时记录日志
+func (sm *StateManager) MarkDownSuppressed(domain, parent string) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	state, exists := sm.states[domain]
+	if !exists {
+		return false
+	}
+	if !state.IsDown {
+		state.LastChangeTime = time.Now()
+	}
+	state.IsDown = true
+	state.Degraded = ""
+	state.DegradedReason = ""
+	state.MutedReason = ""
+	changed := state.SuppressedBy != parent
+	state.SuppressedBy = parent
+	return changed
+}
+
+// downParent 返回目标第一个已判定故障的上游依赖，无时返回空
+func (s *Scheduler) downParent(target string) string {
+	s.configMu.RLock()
+	parents := s.cfg.Targets[target].DependsOn
+	s.configMu.RUnlock()
+
+	for _, parent := range parents {
+		if s.stateManager.IsDown(parent) {
+			return parent
+		}
+	}
+	return ""
+}
+
+// failingParent 返回目标第一个正在连续失败但尚未判定故障的上游依赖，无时返回空
+func (s *Scheduler) failingParent(target string) string {
+	s.configMu.RLock()
+	parents := s.cfg.Targets[target].DependsOn
+	s.configMu.RUnlock()
+
+	for _, parent := range parents {
+		if !s.stateManager.IsDown(parent) && s.stateManager.GetFailCount(parent) > 0 {
+			return parent
+		}
+	}
+	return ""
+}
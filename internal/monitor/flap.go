@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"dnsfailover/internal/config"
+)
+
+// RecordResult 记录一次检测结果并更新抖动状态
+// 返回当前是否处于抖动状态、本次是否进入/退出抖动，以及最近窗口内的状态变化率（%）
+func (sm *StateManager) RecordResult(domain string, success bool, cfg config.FlapConfig) (flapping, changed bool, rate int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	state, exists := sm.states[domain]
+	if !exists {
+		return false, false, 0
+	}
+
+	// 关闭抖动检测时清空历史，正在抖动的目标视为已稳定
+	if !cfg.Enabled || cfg.Window <= 0 {
+		changed = state.Flapping
+		state.History = nil
+		state.Flapping = false
+		state.FlapRate = 0
+		return false, changed, 0
+	}
+
+	state.History = append(state.History, success)
+	if len(state.History) > cfg.Window {
+		state.History = state.History[len(state.History)-cfg.Window:]
+	}
+	state.FlapRate = flapRate(state.History)
+
+	switch {
+	case !state.Flapping && len(state.History) >= cfg.Window && state.FlapRate >= cfg.HighThreshold:
+		state.Flapping = true
+		changed = true
+	case state.Flapping && state.FlapRate < cfg.LowThreshold:
+		state.Flapping = false
+		changed = true
+	}
+	return state.Flapping, changed, state.FlapRate
+}
+
+// flapRate 计算状态变化率：相邻两次检测结果不同的次数占比（%）
+func flapRate(history []bool) int {
+	if len(history) < 2 {
+		return 0
+	}
+
+	changes := 0
+	for i := 1; i < len(history); i++ {
+		if history[i] != history[i-1] {
+			changes++
+		}
+	}
+	return changes * 100 / (len(history) - 1)
+}
@@ -0,0 +1,215 @@
+package monitor
+
+import (
+	"context"
+	"dnsfailover/internal/config"
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/probe"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProbePoolStats 检测工作池统计
+type ProbePoolStats struct {
+	MaxConcurrency  int            `json:"max_concurrency"`   // 全局并发上限
+	Limits          map[string]int `json:"limits"`            // 各类型并发上限
+	Queued          int64          `json:"queued"`            // 当前排队等待的检测数
+	Running         int64          `json:"running"`           // 正在执行的检测数
+	Completed       int64          `json:"completed"`         // 累计完成的检测数
+	Canceled        int64          `json:"canceled"`          // 累计因停止服务或配置变更而取消的检测数
+	AvgQueueMs      float64        `json:"avg_queue_ms"`      // 平均排队等待时间
+	LastMaxQueueMs  float64        `json:"last_max_queue_ms"` // 最近一轮检测的最长排队等待时间
+	Cycles          int64          `json:"cycles"`            // 累计完成的检测轮数
+	SkippedCycles   int64          `json:"skipped_cycles"`    // 因上一轮未完成而跳过的轮数
+	LastCycleMs     float64        `json:"last_cycle_ms"`     // 最近一轮检测的总耗时
+	CycleInProgress bool           `json:"cycle_in_progress"` // 当前是否有检测轮在执行
+}
+
+// probeJob 一次待执行的检测
+type probeJob struct {
+	ctx               context.Context
+	target            string
+	probeType         probe.ProbeType
+	timeout           time.Duration
+	failThreshold     int
+	recoveryThreshold int
+	enqueuedAt        time.Time
+	done              func()
+}
+
+// probePool 检测工作池
+// 每种探针类型有独立的队列与工作协程（数量即该类型的并发上限），执行前再占用全局并发名额，
+// 某一类型排队不会阻塞其他类型，总并发不超过全局上限
+type probePool struct {
+	run    func(*probeJob)
+	max    int
+	limits map[probe.ProbeType]int
+	queues map[probe.ProbeType]chan *probeJob
+	global chan struct{}
+	wg     sync.WaitGroup
+
+	queued       atomic.Int64
+	running      atomic.Int64
+	started      atomic.Int64
+	completed    atomic.Int64
+	canceled     atomic.Int64
+	totalWait    atomic.Int64 // 纳秒
+	cycleMaxWait atomic.Int64 // 本轮最长排队时间（纳秒）
+	lastMaxWait  atomic.Int64 // 上一轮最长排队时间（纳秒）
+	cycles       atomic.Int64
+	skipped      atomic.Int64
+	lastCycle    atomic.Int64 // 纳秒
+	inCycle      atomic.Bool
+}
+
+// newProbePool 创建检测工作池，run 为实际执行检测的函数
+func newProbePool(cfg *config.ProbePoolConfig, run func(*probeJob)) *probePool {
+	total := cfg.MaxConcurrency
+	if total <= 0 {
+		total = 64
+	}
+	limit := func(n int) int {
+		if n <= 0 || n > total {
+			return total
+		}
+		return n
+	}
+
+	return &probePool{
+		run: run,
+		max: total,
+		limits: map[probe.ProbeType]int{
+			probe.TypePing: limit(cfg.PingConcurrency),
+			probe.TypeTCP:  limit(cfg.TCPConcurrency),
+			probe.TypeHTTP: limit(cfg.HTTPConcurrency),
+		},
+	}
+}
+
+// Start 启动工作协程
+func (p *probePool) Start() {
+	p.global = make(chan struct{}, p.max)
+	p.queues = make(map[probe.ProbeType]chan *probeJob, len(p.limits))
+	for probeType, limit := range p.limits {
+		queue := make(chan *probeJob)
+		p.queues[probeType] = queue
+		for i := 0; i < limit; i++ {
+			p.wg.Add(1)
+			go p.worker(queue)
+		}
+	}
+	logger.Infof("检测工作池已启动 (全局并发 %d, Ping %d, TCP %d, HTTP %d)",
+		p.max, p.limits[probe.TypePing], p.limits[probe.TypeTCP], p.limits[probe.TypeHTTP])
+}
+
+// Stop 停止工作协程（调用前需确保没有正在执行的检测轮）
+func (p *probePool) Stop() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
+
+// worker 从类型队列取出检测，占用全局名额后执行
+func (p *probePool) worker(queue chan *probeJob) {
+	defer p.wg.Done()
+
+	for job := range queue {
+		p.global <- struct{}{}
+		p.queued.Add(-1)
+
+		// 排队期间已取消的检测直接跳过
+		if job.ctx.Err() != nil {
+			p.canceled.Add(1)
+		} else {
+			p.started.Add(1)
+			p.recordWait(time.Since(job.enqueuedAt))
+
+			p.running.Add(1)
+			p.run(job)
+			p.running.Add(-1)
+			if job.ctx.Err() != nil {
+				p.canceled.Add(1)
+			} else {
+				p.completed.Add(1)
+			}
+		}
+
+		<-p.global
+		job.done()
+	}
+}
+
+// recordWait 记录排队等待时间
+func (p *probePool) recordWait(wait time.Duration) {
+	p.totalWait.Add(int64(wait))
+	for {
+		current := p.cycleMaxWait.Load()
+		if int64(wait) <= current || p.cycleMaxWait.CompareAndSwap(current, int64(wait)) {
+			return
+		}
+	}
+}
+
+// RunCycle 执行一轮检测并等待全部完成；上一轮仍在执行时跳过本轮，返回是否执行
+func (p *probePool) RunCycle(jobs []*probeJob) bool {
+	if !p.inCycle.CompareAndSwap(false, true) {
+		p.skipped.Add(1)
+		return false
+	}
+	defer p.inCycle.Store(false)
+
+	start := time.Now()
+	p.cycleMaxWait.Store(0)
+
+	// 按类型分组，各类型由独立的协程入队，互不阻塞
+	var wg sync.WaitGroup
+	byType := make(map[probe.ProbeType][]*probeJob)
+	for _, job := range jobs {
+		job.enqueuedAt = start
+		job.done = wg.Done
+		byType[job.probeType] = append(byType[job.probeType], job)
+	}
+	wg.Add(len(jobs))
+	p.queued.Add(int64(len(jobs)))
+
+	for probeType, typeJobs := range byType {
+		go func(queue chan *probeJob, typeJobs []*probeJob) {
+			for _, job := range typeJobs {
+				queue <- job
+			}
+		}(p.queues[probeType], typeJobs)
+	}
+	wg.Wait()
+
+	p.lastMaxWait.Store(p.cycleMaxWait.Load())
+	p.lastCycle.Store(int64(time.Since(start)))
+	p.cycles.Add(1)
+	return true
+}
+
+// Stats 获取工作池统计
+func (p *probePool) Stats() ProbePoolStats {
+	stats := ProbePoolStats{
+		MaxConcurrency: p.max,
+		Limits: map[string]int{
+			"ping": p.limits[probe.TypePing],
+			"tcp":  p.limits[probe.TypeTCP],
+			"http": p.limits[probe.TypeHTTP],
+		},
+		Queued:          p.queued.Load(),
+		Running:         p.running.Load(),
+		Completed:       p.completed.Load(),
+		Canceled:        p.canceled.Load(),
+		LastMaxQueueMs:  float64(p.lastMaxWait.Load()) / float64(time.Millisecond),
+		Cycles:          p.cycles.Load(),
+		SkippedCycles:   p.skipped.Load(),
+		LastCycleMs:     float64(p.lastCycle.Load()) / float64(time.Millisecond),
+		CycleInProgress: p.inCycle.Load(),
+	}
+	if started := p.started.Load(); started > 0 {
+		stats.AvgQueueMs = float64(p.totalWait.Load()) / float64(started) / float64(time.Millisecond)
+	}
+	return stats
+}
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/probe"
+	"dnsfailover/internal/storage"
+	"time"
+)
+
+// toCheckResult 将探针结果转换为可持久化的检测结果
+func toCheckResult(result *probe.Result) *storage.CheckResult {
+	r := &storage.CheckResult{
+		Target:        result.Target,
+		ProbeType:     string(result.Type),
+		Success:       result.Success,
+		LatencyMs:     milliseconds(result.Latency),
+		ResolvedIP:    result.ResolvedIP,
+		StatusCode:    result.StatusCode,
+		PacketLoss:    result.PacketLoss,
+		ErrorCategory: string(result.ErrorCategory),
+		Metrics:       result.Metrics,
+		CheckedAt:     formatTime(result.CheckedAt),
+		Timings: storage.CheckTimings{
+			DNSMs:     milliseconds(result.Timings.DNS),
+			ConnectMs: milliseconds(result.Timings.Connect),
+			TLSMs:     milliseconds(result.Timings.TLS),
+			TTFBMs:    milliseconds(result.Timings.TTFB),
+			TotalMs:   milliseconds(result.Timings.Total),
+		},
+	}
+	if result.RTT != nil {
+		r.RTT = &storage.RTTStats{
+			MinMs:    milliseconds(result.RTT.Min),
+			AvgMs:    milliseconds(result.RTT.Avg),
+			MaxMs:    milliseconds(result.RTT.Max),
+			StdDevMs: milliseconds(result.RTT.StdDev),
+		}
+	}
+	if result.Error != nil {
+		r.Error = result.Error.Error()
+	}
+	return r
+}
+
+// milliseconds 将耗时转换为毫秒（保留小数）
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// saveResult 持久化检测结果，失败只记录日志不影响检测流程
+func saveResult(r *storage.CheckResult) {
+	store := storage.GetStorage()
+	if store == nil {
+		return
+	}
+	if err := store.InsertCheckResult(r); err != nil {
+		logger.Warnf("保存检测结果失败 %s: %v", r.Target, err)
+	}
+}
@@ -1,52 +1,85 @@
 package monitor
 
 import (
+	"context"
 	"dnsfailover/internal/config"
+	"dnsfailover/internal/incident"
 	"dnsfailover/internal/logger"
+	"dnsfailover/internal/maintenance"
 	"dnsfailover/internal/probe"
+	"dnsfailover/internal/silence"
+	"dnsfailover/internal/storage"
 	"dnsfailover/internal/webhook"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// configSyncInterval 检查数据库中配置版本变化的间隔（用于感知 CLI 回滚/导入）
+const configSyncInterval = 5 * time.Second
+
 // Scheduler 监控调度器
 type Scheduler struct {
-	cfg           *config.Config
-	stateManager  *StateManager
-	webhookClient *webhook.Client
-	ticker        *time.Ticker
-	stopChan      chan bool
-	isRunning     bool
-	mu            sync.Mutex
-	configMu      sync.RWMutex // 配置读写锁
+	cfg             *config.Config
+	stateManager    *StateManager
+	webhookClient   *webhook.Client
+	dispatcher      *webhook.Dispatcher
+	incidents       *incident.Manager
+	ticker          *time.Ticker
+	isRunning       bool
+	mu              sync.Mutex
+	configMu        sync.RWMutex   // 配置读写锁
+	appliedRevision int64          // 当前已生效的配置版本号
+	pool            *probePool     // 检测工作池
+	cycleWg         sync.WaitGroup // 正在执行的检测轮
+	loopDone        chan struct{}  // 监控主循环退出后关闭
+
+	// ctx 在停止服务时取消；cycleCtx 派生自 ctx，配置变更时取消并重建，用于中止进行中的检测
+	ctx         context.Context
+	cancel      context.CancelFunc
+	cycleMu     sync.Mutex
+	cycleCtx    context.Context
+	cycleCancel context.CancelFunc
+
+	// 配置由配置文件管理时不从数据库同步配置版本
+	fileManaged bool
 
 	// 检测器
 	pingChecker *probe.PingChecker
 	tcpChecker  *probe.TCPChecker
 	httpChecker *probe.HTTPChecker
+
+	pingFallbackLogged atomic.Bool // 已记录 Ping 切换为非特权模式的日志
 }
 
 // NewScheduler 创建监控调度器
 func NewScheduler(cfg *config.Config) *Scheduler {
 	stateManager := NewStateManager()
 
-	// 初始化静默期配置
-	if cfg.Webhook.SilencePeriod > 0 {
-		DefaultSilenceDuration = time.Duration(cfg.Webhook.SilencePeriod) * time.Second
-	}
+	webhookClient := webhook.NewClient(&cfg.Webhook)
 
 	s := &Scheduler{
 		cfg:           cfg,
 		stateManager:  stateManager,
-		webhookClient: webhook.NewClient(&cfg.Webhook),
-		stopChan:      make(chan bool),
+		webhookClient: webhookClient,
+		dispatcher:    webhook.NewDispatcher(webhookClient, &cfg.Dispatch),
+		incidents:     incident.NewManager(webhookClient),
 		isRunning:     false,
 		pingChecker:   probe.NewPingChecker(),
 		tcpChecker:    probe.NewTCPChecker(),
 		httpChecker:   probe.NewHTTPChecker(5 * time.Second),
 	}
 
+	s.pool = newProbePool(&cfg.Probe, func(job *probeJob) {
+		s.checkTarget(job.ctx, job.target, job.probeType, job.timeout, job.failThreshold, job.recoveryThreshold)
+	})
+
+	if store := storage.GetStorage(); store != nil {
+		s.appliedRevision, _ = store.GetLatestConfigRevisionID()
+	}
+
 	return s
 }
 
@@ -69,8 +102,19 @@ func (s *Scheduler) Start() error {
 	s.ticker = time.NewTicker(time.Duration(frequency) * time.Second)
 	s.isRunning = true
 
-	// 启动监控循环
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.cycleMu.Lock()
+	s.cycleCtx, s.cycleCancel = context.WithCancel(s.ctx)
+	s.cycleMu.Unlock()
+
+	s.loopDone = make(chan struct{})
+
+	// 启动检测工作池、监控循环、告警分发器、Webhook 重试队列与告警升级
+	s.pool.Start()
 	go s.monitorLoop()
+	s.dispatcher.Start()
+	s.webhookClient.Start()
+	s.incidents.Start()
 
 	// 打印启动信息
 	s.printStartupInfo()
@@ -160,8 +204,15 @@ func (s *Scheduler) Stop() error {
 		return fmt.Errorf("监控服务未在运行")
 	}
 
+	// 取消进行中的检测并等待本轮结束，再排空告警分发队列
 	s.ticker.Stop()
-	s.stopChan <- true
+	s.cancel()
+	<-s.loopDone
+	s.cycleWg.Wait()
+	s.pool.Stop()
+	s.dispatcher.Stop()
+	s.webhookClient.Stop()
+	s.incidents.Stop()
 
 	s.isRunning = false
 	logger.Info("监控服务已停止")
@@ -177,23 +228,165 @@ func (s *Scheduler) IsRunning() bool {
 
 // monitorLoop 监控主循环
 func (s *Scheduler) monitorLoop() {
+	defer close(s.loopDone)
+	syncTicker := time.NewTicker(configSyncInterval)
+	defer syncTicker.Stop()
+
 	// 立即执行一次检测
-	s.checkAllTargets()
+	s.startCycle()
 
 	for {
 		select {
 		case <-s.ticker.C:
-			s.checkAllTargets()
-		case <-s.stopChan:
+			s.startCycle()
+		case <-syncTicker.C:
+			s.syncConfigRevision()
+		case <-s.ctx.Done():
 			return
 		}
 	}
 }
 
-// checkAllTargets 检查所有目标
-func (s *Scheduler) checkAllTargets() {
-	var wg sync.WaitGroup
+// targetTypes 返回当前配置中所有检测目标及其类型，调用方需持有 configMu
+func (s *Scheduler) targetTypes() map[string]probe.ProbeType {
+	targets := make(map[string]probe.ProbeType)
+	for _, t := range s.cfg.Ping.Domains {
+		targets[t] = probe.TypePing
+	}
+	for _, t := range s.cfg.Tcp.Domains {
+		targets[t] = probe.TypeTCP
+	}
+	for _, t := range s.cfg.Http.Domains {
+		targets[t] = probe.TypeHTTP
+	}
+	return targets
+}
+
+// SetFileManaged 配置由配置文件管理，不再从数据库同步配置版本（需在 Start 前调用）
+func (s *Scheduler) SetFileManaged() {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	s.fileManaged = true
+}
+
+// CurrentConfig 获取当前生效配置的副本
+func (s *Scheduler) CurrentConfig() *storage.FullConfig {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
+	return config.ToStorageConfig(s.cfg)
+}
+
+// ApplyConfig 热更新配置：同步目标状态、检测频率与 Webhook 设置
+func (s *Scheduler) ApplyConfig(stored *storage.FullConfig, revision int64) {
+	s.configMu.Lock()
+	oldTargets := s.targetTypes()
+	config.ApplyStorageConfig(s.cfg, stored)
+	newTargets := s.targetTypes()
+	webhookCfg := s.cfg.Webhook
+	s.appliedRevision = revision
+	s.configMu.Unlock()
+
+	// 新增目标初始化状态，移除的目标清理状态
+	for target, probeType := range newTargets {
+		if _, exists := oldTargets[target]; !exists {
+			s.stateManager.InitDomain(target)
+			logger.Infof("[%-4s] ➕ 新增监控目标: %s", probeType, target)
+		}
+	}
+	for target, probeType := range oldTargets {
+		if _, exists := newTargets[target]; !exists {
+			s.stateManager.RemoveDomain(target)
+			logger.Infof("[%-4s] ➖ 移除监控目标: %s", probeType, target)
+		}
+	}
+
+	// 取消按旧配置进行中的检测，下一轮按新配置执行
+	s.cancelCycle()
+
+	// 更新 Webhook 客户端（静默期等其余配置在使用时按锁读取）
+	s.webhookClient.UpdateConfig(&webhookCfg)
+
+	// 更新主循环频率
+	frequency := s.getMinFrequency()
+	s.mu.Lock()
+	if s.isRunning && s.ticker != nil {
+		s.ticker.Reset(time.Duration(frequency) * time.Second)
+	}
+	s.mu.Unlock()
+
+	if revision == 0 {
+		logger.Infof("配置已热更新 (检测频率 %d 秒)", frequency)
+		return
+	}
+	logger.Infof("配置已热更新 (版本 #%d, 检测频率 %d 秒)", revision, frequency)
+}
+
+// cancelCycle 取消进行中的检测轮，之后启动的检测使用新的上下文
+func (s *Scheduler) cancelCycle() {
+	s.cycleMu.Lock()
+	defer s.cycleMu.Unlock()
 
+	if s.cycleCancel == nil {
+		return
+	}
+	if s.pool.Stats().CycleInProgress {
+		logger.Info("配置已变更，取消进行中的检测")
+	}
+	s.cycleCancel()
+	s.cycleCtx, s.cycleCancel = context.WithCancel(s.ctx)
+}
+
+// syncConfigRevision 检查数据库中是否有新的配置版本（如 CLI 回滚），有则热更新
+func (s *Scheduler) syncConfigRevision() {
+	store := storage.GetStorage()
+	if store == nil {
+		return
+	}
+
+	latest, err := store.GetLatestConfigRevisionID()
+	if err != nil {
+		logger.Warnf("检查配置版本失败: %v", err)
+		return
+	}
+
+	s.configMu.RLock()
+	applied := s.appliedRevision
+	fileManaged := s.fileManaged
+	s.configMu.RUnlock()
+
+	if fileManaged || latest == 0 || latest == applied {
+		return
+	}
+
+	rev, err := store.GetConfigRevision(latest)
+	if err != nil || rev == nil {
+		logger.Warnf("读取配置版本 #%d 失败: %v", latest, err)
+		return
+	}
+
+	logger.Infof("检测到新的配置版本 #%d (%s)，正在应用", rev.ID, rev.Author)
+	s.ApplyConfig(rev.Config, rev.ID)
+}
+
+// startCycle 在后台执行一轮检测，不阻塞主循环；上一轮仍未完成时跳过本轮，避免检测重叠
+func (s *Scheduler) startCycle() {
+	s.cycleMu.Lock()
+	ctx := s.cycleCtx
+	s.cycleMu.Unlock()
+
+	s.cycleWg.Add(1)
+	go func() {
+		defer s.cycleWg.Done()
+		if !s.checkAllTargets(ctx) {
+			logger.Warnf("上一轮检测尚未完成，跳过本轮检测 (可调大检测频率或并发上限)")
+		}
+	}()
+}
+
+// checkAllTargets 将所有目标提交到检测工作池并等待完成，返回本轮是否执行
+func (s *Scheduler) checkAllTargets(ctx context.Context) bool {
 	// 获取配置（加读锁）
 	s.configMu.RLock()
 	pingEnabled := s.cfg.Ping.Enabled
@@ -201,99 +394,170 @@ func (s *Scheduler) checkAllTargets() {
 	copy(pingTargets, s.cfg.Ping.Domains)
 	pingTimeout := time.Duration(s.cfg.Ping.Timeout) * time.Second
 	pingFailCount := s.cfg.Ping.FailCount
+	pingRecoveryCount := s.cfg.Ping.RecoveryCount
 
 	tcpEnabled := s.cfg.Tcp.Enabled
 	tcpTargets := make([]string, len(s.cfg.Tcp.Domains))
 	copy(tcpTargets, s.cfg.Tcp.Domains)
 	tcpTimeout := time.Duration(s.cfg.Tcp.Timeout) * time.Second
 	tcpFailCount := s.cfg.Tcp.FailCount
+	tcpRecoveryCount := s.cfg.Tcp.RecoveryCount
 
 	httpEnabled := s.cfg.Http.Enabled
 	httpTargets := make([]string, len(s.cfg.Http.Domains))
 	copy(httpTargets, s.cfg.Http.Domains)
 	httpTimeout := time.Duration(s.cfg.Http.Timeout) * time.Second
 	httpFailCount := s.cfg.Http.FailCount
+	httpRecoveryCount := s.cfg.Http.RecoveryCount
 	s.configMu.RUnlock()
 
-	// 并发检测Ping目标（如果启用）
-	if pingEnabled {
-		for _, target := range pingTargets {
-			wg.Add(1)
-			go func(t string) {
-				defer wg.Done()
-				s.checkTarget(t, probe.TypePing, pingTimeout, pingFailCount)
-			}(target)
+	var jobs []*probeJob
+	add := func(targets []string, probeType probe.ProbeType, timeout time.Duration, failThreshold, recoveryThreshold int) {
+		for _, target := range targets {
+			jobs = append(jobs, &probeJob{
+				ctx:               ctx,
+				target:            target,
+				probeType:         probeType,
+				timeout:           timeout,
+				failThreshold:     failThreshold,
+				recoveryThreshold: recoveryThreshold,
+			})
 		}
 	}
-
-	// 并发检测TCP目标（如果启用）
+	if pingEnabled {
+		add(pingTargets, probe.TypePing, pingTimeout, pingFailCount, pingRecoveryCount)
+	}
 	if tcpEnabled {
-		for _, target := range tcpTargets {
-			wg.Add(1)
-			go func(t string) {
-				defer wg.Done()
-				s.checkTarget(t, probe.TypeTCP, tcpTimeout, tcpFailCount)
-			}(target)
-		}
+		add(tcpTargets, probe.TypeTCP, tcpTimeout, tcpFailCount, tcpRecoveryCount)
 	}
-
-	// 并发检测HTTP目标（如果启用）
 	if httpEnabled {
-		for _, target := range httpTargets {
-			wg.Add(1)
-			go func(t string) {
-				defer wg.Done()
-				s.checkTarget(t, probe.TypeHTTP, httpTimeout, httpFailCount)
-			}(target)
-		}
+		add(httpTargets, probe.TypeHTTP, httpTimeout, httpFailCount, httpRecoveryCount)
 	}
 
-	wg.Wait()
+	return s.pool.RunCycle(jobs)
+}
+
+// pingOptions 返回目标的 Ping 检测参数，未配置的项使用默认值
+func (s *Scheduler) pingOptions(target string) probe.PingOptions {
+	opts := probe.DefaultPingOptions()
+
+	s.configMu.RLock()
+	custom := s.cfg.Targets[target].Ping
+	s.configMu.RUnlock()
+	if custom == nil {
+		return opts
+	}
+
+	if custom.Count > 0 {
+		opts.Count = custom.Count
+	}
+	if custom.Interval > 0 {
+		opts.Interval = time.Duration(custom.Interval) * time.Millisecond
+	}
+	if custom.Size > 0 {
+		opts.Size = custom.Size
+	}
+	if custom.TTL > 0 {
+		opts.TTL = custom.TTL
+	}
+	if custom.LossThreshold > 0 {
+		opts.LossThreshold = float64(custom.LossThreshold)
+	}
+	return opts
 }
 
 // checkTarget 检查单个目标
-func (s *Scheduler) checkTarget(target string, probeType probe.ProbeType, timeout time.Duration, failThreshold int) {
+func (s *Scheduler) checkTarget(ctx context.Context, target string, probeType probe.ProbeType, timeout time.Duration, failThreshold, recoveryThreshold int) {
 	// 格式化类型标签，保持对齐
 	typeTag := fmt.Sprintf("%-4s", probeType)
 
-	// 检查是否在静默期内
-	if s.stateManager.IsSilenced(target) {
-		remaining := s.stateManager.GetSilenceRemaining(target)
-		logger.Debugf("[%s] ⏸ %s 处于静默期，剩余 %v", typeTag, target, remaining.Round(time.Second))
-		return
-	}
-
-	// 执行检测
+	// 执行检测（静默期内照常检测，静默期只限制重复告警）
 	var result *probe.Result
 	switch probeType {
 	case probe.TypePing:
-		result = s.pingChecker.Check(target, timeout)
+		result = s.pingChecker.CheckWithOptions(ctx, target, timeout, s.pingOptions(target))
+		if s.pingChecker.Unprivileged() && s.pingFallbackLogged.CompareAndSwap(false, true) {
+			logger.Warn("没有原始套接字权限，Ping 检测已切换为非特权 UDP 模式")
+		}
 	case probe.TypeTCP:
-		result = s.tcpChecker.Check(target, timeout)
+		result = s.tcpChecker.Check(ctx, target, timeout)
 	case probe.TypeHTTP:
-		result = s.httpChecker.Check(target, timeout)
+		result = s.httpChecker.Check(ctx, target, timeout)
 	default:
 		logger.Errorf("[%s] 未知的检测类型", typeTag)
 		return
 	}
 
+	// 检测被取消（停止服务或配置变更）时不计入结果
+	if ctx.Err() != nil {
+		logger.Debugf("[%s] %s 检测已取消", typeTag, target)
+		return
+	}
+
 	// 获取当前状态
 	state := s.stateManager.GetState(target)
 	wasDown := state.IsDown
+	suppressedBy := state.SuppressedBy
+	mutedReason := state.MutedReason
+	checkResult := toCheckResult(result)
+	s.stateManager.RecordCheck(target, checkResult)
+	saveResult(checkResult)
+
+	// 抖动检测：进入抖动时发送一次抖动告警，抖动期间不逐次告警，稳定后发送稳定告警
+	s.configMu.RLock()
+	flapCfg := s.cfg.Flap
+	s.configMu.RUnlock()
+	flapping, flapChanged, flapRate := s.stateManager.RecordResult(target, result.Success, flapCfg)
+	if flapChanged {
+		if flapping {
+			s.notifyFlapping(probeType, target, flapRate)
+		} else {
+			defer s.notifyStabilized(probeType, target, flapRate)
+		}
+	}
 
 	if result.Success {
 		// 检测成功
 		logger.Infof("[%s] ✓ %s (延迟: %v)", typeTag, target, result.Latency)
 
-		// 如果之前是故障状态，现在恢复了，发送恢复通知
+		// 如果之前是故障状态，连续成功达到恢复阈值后发送恢复通知（维护窗口或静音期间只关闭事件）
+		// 抖动期间不发送恢复通知，事件保持打开，直到稳定后再关闭
 		if wasDown {
-			logger.Infof("[%s] ✓ %s 已恢复正常", typeTag, target)
-			s.webhookClient.SendRecoveryAlert(string(probeType), target)
+			threshold := s.recoveryThreshold(target, recoveryThreshold)
+			if successCount := s.stateManager.IncrementSuccessCount(target); successCount < threshold {
+				logger.Infof("[%s] ↻ %s 恢复中 (%d/%d)", typeTag, target, successCount, threshold)
+				return
+			}
+			if suppressedBy != "" {
+				// 故障告警已被上游抑制，恢复时同样不通知
+				logger.Infof("[%s] ✓ %s 已恢复正常，故障期间被上游 %s 抑制，不发送恢复通知", typeTag, target, suppressedBy)
+			} else if mutedReason != "" {
+				// 故障发生在维护窗口或静音期间，未发送故障告警，恢复时同样不通知
+				logger.Infof("[%s] ✓ %s 已恢复正常，故障期间%s，不发送恢复通知", typeTag, target, mutedReason)
+			} else if flapping {
+				logger.Infof("[%s] ~ %s 已恢复正常，抖动中不发送恢复通知", typeTag, target)
+			} else {
+				logger.Infof("[%s] ✓ %s 已恢复正常", typeTag, target)
+				alert := s.newAlert(webhook.AlertTypeRecovery, probeType, target)
+				alert.Duration = int64(s.stateManager.GetIncidentDuration(target).Seconds())
+				alert.Incident = s.incidents.Resolve(target, string(probeType))
+				if reason := s.suppression(target); reason != "" {
+					logger.Infof("[%s] ⏸ %s %s，不发送恢复通知", typeTag, target, reason)
+				} else {
+					s.dispatcher.Dispatch(alert)
+				}
+			}
+		} else if !flapping {
+			// 进程重启前遗留的未恢复事件
+			s.incidents.Resolve(target, string(probeType))
 		}
 
-		// 重置失败计数和静默期
+		// 重置失败计数和静默期（恢复时立即通知，不受静默期限制）
 		s.stateManager.ResetFailCount(target)
 		s.stateManager.ClearSilence(target)
+
+		// 检查性能降级
+		s.checkPerformance(target, probeType, result)
 	} else {
 		// 检测失败
 		currentFailCount := s.stateManager.IncrementFailCount(target)
@@ -303,18 +567,311 @@ func (s *Scheduler) checkTarget(target string, probeType probe.ProbeType, timeou
 		}
 		logger.Warnf("[%s] ✗ %s 失败 (%d/%d) - %s", typeTag, target, currentFailCount, failThreshold, errMsg)
 
-		// 达到阈值，触发告警
-		if currentFailCount >= failThreshold {
-			logger.Errorf("[%s] ⚠ %s 触发告警 (连续失败 %d 次)，进入静默期 %v", typeTag, target, currentFailCount, DefaultSilenceDuration)
-			s.webhookClient.SendDownAlert(string(probeType), target, currentFailCount, failThreshold, errMsg)
-			s.stateManager.MarkDown(target)
+		// 未达到阈值
+		if currentFailCount < failThreshold {
+			return
+		}
+
+		// 上游依赖故障时记录故障状态，但不告警、不创建故障事件（已单独告警的故障照常跟进）
+		// 上游恢复后仍失败时按新故障告警
+		alerted := wasDown && suppressedBy == "" && mutedReason == ""
+		if parent := s.downParent(target); parent != "" && !alerted {
+			if s.stateManager.MarkDownSuppressed(target, parent) {
+				logger.Warnf("[%s] ⇣ %s 判定故障 (连续失败 %d 次)，被上游 %s 抑制告警", typeTag, target, currentFailCount, parent)
+			}
+			return
+		}
+
+		// 刚达到阈值时上游正在连续失败，延后一轮告警，等待上游判定结果，避免下游先于上游告警
+		if parent := s.failingParent(target); parent != "" && !alerted && currentFailCount == failThreshold {
+			logger.Infof("[%s] ⏳ %s 达到失败阈值，上游 %s 正在连续失败，延后一轮告警", typeTag, target, parent)
+			return
+		}
+
+		// 维护窗口或静音期间照常标记故障，但不告警、不创建故障事件，结束后仍失败时立即告警
+		// 已告警的故障照常保持，期间不发送提醒
+		if reason := s.suppression(target); reason != "" {
+			if !alerted && s.stateManager.MarkDownMuted(target, reason) {
+				logger.Warnf("[%s] ⇣ %s 判定故障 (连续失败 %d 次)，%s，抑制告警", typeTag, target, currentFailCount, reason)
+			} else {
+				logger.Infof("[%s] ⏸ %s %s，抑制告警 (连续失败 %d 次)", typeTag, target, reason, currentFailCount)
+			}
+			return
+		}
+
+		// 抖动期间只记录故障状态与事件，不逐次告警
+		if flapping {
+			if !alerted {
+				logger.Warnf("[%s] ~ %s 判定故障 (连续失败 %d 次)，抖动中不发送故障告警", typeTag, target, currentFailCount)
+				alert := s.newAlert(webhook.AlertTypeDown, probeType, target)
+				alert.Error = errMsg
+				s.incidents.Open(alert)
+				s.stateManager.MarkDownWithSilence(target, s.silenceDuration())
+			}
+			return
+		}
+
+		// 已告警时仅在配置了提醒间隔时发送提醒，且提醒间隔内不重复通知
+		interval := s.reminderInterval()
+		if alerted && interval == 0 {
+			return
+		}
+		if alerted && s.stateManager.IsSilenced(target) {
+			remaining := s.stateManager.GetSilenceRemaining(target)
+			logger.Debugf("[%s] ⏸ %s 处于静默期，剩余 %v", typeTag, target, remaining.Round(time.Second))
+			return
+		}
+
+		// 配置了提醒间隔时按提醒间隔静默，到期发送提醒；否则按静默期静默
+		silence := interval
+		if silence == 0 {
+			silence = s.silenceDuration()
 		}
+		alert := s.newAlert(webhook.AlertTypeDown, probeType, target)
+		alert.FailCount = currentFailCount
+		alert.Threshold = failThreshold
+		alert.Error = errMsg
+		alert.Duration = int64(s.stateManager.GetIncidentDuration(target).Seconds())
+		if alerted {
+			// 持续故障：发送仍未恢复提醒，附带故障持续时间与最近一次错误
+			alert.Reminder = s.stateManager.IncrementReminderCount(target)
+			logger.Errorf("[%s] ⏰ %s 仍未恢复，故障已持续 %v (第 %d 次提醒)，下次提醒间隔 %v",
+				typeTag, target, time.Duration(alert.Duration)*time.Second, alert.Reminder, interval)
+		} else {
+			logger.Errorf("[%s] ⚠ %s 触发告警 (连续失败 %d 次)，进入静默期 %v", typeTag, target, currentFailCount, silence)
+		}
+		alert.Incident = s.incidents.Open(alert)
+		s.dispatcher.Dispatch(alert)
+		s.stateManager.MarkDownWithSilence(target, silence)
+	}
+}
+
+// notifyFlapping 发送抖动告警
+func (s *Scheduler) notifyFlapping(probeType probe.ProbeType, target string, rate int) {
+	typeTag := fmt.Sprintf("%-4s", probeType)
+	logger.Warnf("[%s] ~ %s 状态抖动 (状态变化率 %d%%)，暂停逐次告警", typeTag, target, rate)
+
+	if reason := s.suppression(target); reason != "" {
+		logger.Infof("[%s] ⏸ %s %s，不发送抖动告警", typeTag, target, reason)
+		return
 	}
+	alert := s.newAlert(webhook.AlertTypeFlapping, probeType, target)
+	alert.FlapRate = rate
+	s.dispatcher.Dispatch(alert)
 }
 
-// GetConfig 获取当前配置
-func (s *Scheduler) GetConfig() *config.Config {
+// notifyStabilized 发送稳定告警；稳定在正常状态时关闭抖动期间保留的事件
+func (s *Scheduler) notifyStabilized(probeType probe.ProbeType, target string, rate int) {
+	typeTag := fmt.Sprintf("%-4s", probeType)
+	down := s.stateManager.GetState(target).IsDown
+
+	alert := s.newAlert(webhook.AlertTypeStabilized, probeType, target)
+	alert.FlapRate = rate
+	alert.State = TargetStateUp
+	stateText := "正常"
+	if down {
+		alert.State = TargetStateDown
+		alert.Duration = int64(s.stateManager.GetIncidentDuration(target).Seconds())
+		stateText = "故障"
+	} else {
+		alert.Incident = s.incidents.Resolve(target, string(probeType))
+	}
+	logger.Infof("[%s] ~ %s 已停止抖动 (状态变化率 %d%%)，当前状态: %s", typeTag, target, rate, stateText)
+
+	if reason := s.suppression(target); reason != "" {
+		logger.Infof("[%s] ⏸ %s %s，不发送稳定告警", typeTag, target, reason)
+		return
+	}
+	s.dispatcher.Dispatch(alert)
+}
+
+// reminderInterval 返回持续故障时的提醒间隔，未配置时返回 0 表示不发送提醒
+func (s *Scheduler) reminderInterval() time.Duration {
 	s.configMu.RLock()
 	defer s.configMu.RUnlock()
-	return s.cfg
+
+	return time.Duration(s.cfg.Webhook.ReminderInterval) * time.Second
+}
+
+// silenceDuration 返回配置的静默期，未配置时使用默认值
+func (s *Scheduler) silenceDuration() time.Duration {
+	s.configMu.RLock()
+	period := s.cfg.Webhook.SilencePeriod
+	s.configMu.RUnlock()
+
+	if period > 0 {
+		return time.Duration(period) * time.Second
+	}
+	return DefaultSilenceDuration
+}
+
+// recoveryThreshold 返回目标的恢复阈值：目标配置优先，否则使用探针配置，至少为 1
+func (s *Scheduler) recoveryThreshold(target string, probeDefault int) int {
+	s.configMu.RLock()
+	threshold := s.cfg.Targets[target].RecoveryCount
+	s.configMu.RUnlock()
+
+	if threshold <= 0 {
+		threshold = probeDefault
+	}
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return threshold
+}
+
+// suppression 返回目标当前的告警抑制原因（维护窗口、手动静音或上游依赖故障），不抑制时返回空
+func (s *Scheduler) suppression(target string) string {
+	s.configMu.RLock()
+	tags := s.cfg.Targets[target].Tags
+	s.configMu.RUnlock()
+
+	if mw := maintenance.ActiveFor(target, tags); mw != nil {
+		return fmt.Sprintf("处于维护窗口「%s」", mw.Name)
+	}
+	if ts := silence.Active(target); ts != nil {
+		return fmt.Sprintf("已被 %s 静音至 %s", ts.CreatedBy, ts.ExpiresAt)
+	}
+	if parent := s.downParent(target); parent != "" {
+		return fmt.Sprintf("上游 %s 故障中", parent)
+	}
+	return ""
+}
+
+// newAlert 创建告警，附带目标的标签与告警级别供路由使用
+func (s *Scheduler) newAlert(alertType webhook.AlertType, probeType probe.ProbeType, target string) *webhook.Alert {
+	s.configMu.RLock()
+	meta := s.cfg.Targets[target]
+	s.configMu.RUnlock()
+
+	severity := webhook.Severity(meta.Severity)
+	if severity == "" {
+		severity = webhook.SeverityCritical
+	}
+
+	return &webhook.Alert{
+		Type:      alertType,
+		Severity:  severity,
+		ProbeType: string(probeType),
+		Target:    target,
+		Tags:      meta.Tags,
+	}
+}
+
+// 检测目标的运行状态
+const (
+	TargetStateUp         = "up"
+	TargetStateDown       = "down"
+	TargetStateRecovering = "recovering" // 故障后已连续成功，但未达到恢复阈值
+	TargetStateFlapping   = "flapping"   // 状态频繁变化，暂停逐次告警
+	TargetStateDegraded   = "degraded"   // 可达但延迟、丢包或抖动超过阈值
+	TargetStateDependent  = "dependent"  // 故障，但上游依赖故障，告警被抑制
+)
+
+// TargetStatus 检测目标的运行时状态
+type TargetStatus struct {
+	Target         string               `json:"target"`
+	ProbeType      string               `json:"probe_type"`
+	Tags           []string             `json:"tags"`
+	Severity       string               `json:"severity"`
+	State          string               `json:"state"` // up | down | recovering | flapping | degraded | dependent
+	Down           bool                 `json:"down"`
+	FailCount      int                  `json:"fail_count"`
+	SuccessCount   int                  `json:"success_count"`      // 恢复中的连续成功次数
+	FlapRate       int                  `json:"flap_rate"`          // 最近窗口内的状态变化率（%）
+	Degraded       string               `json:"degraded,omitempty"` // 性能降级级别 warning/critical
+	DegradedReason string               `json:"degraded_reason,omitempty"`
+	LatencyMs      int64                `json:"latency_ms"`
+	JitterMs       int64                `json:"jitter_ms"`
+	PacketLoss     float64              `json:"packet_loss"`
+	Since          string               `json:"since,omitempty"` // 进入当前状态（正常/故障）的时间
+	LastCheckAt    string               `json:"last_check_at,omitempty"`
+	LastAlertAt    string               `json:"last_alert_at,omitempty"`
+	LastResult     *storage.CheckResult `json:"last_result,omitempty"`   // 最近一次检测的结构化结果
+	DependsOn      []string             `json:"depends_on,omitempty"`    // 上游依赖目标
+	SuppressedBy   string               `json:"suppressed_by,omitempty"` // 故障告警被哪个上游抑制
+	MutedReason    string               `json:"muted_reason,omitempty"`  // 故障告警被维护窗口或静音抑制的原因
+}
+
+// TargetStatuses 获取所有检测目标的运行时状态（按探针类型、目标排序）
+func (s *Scheduler) TargetStatuses() []*TargetStatus {
+	s.configMu.RLock()
+	targets := s.targetTypes()
+	metas := make(map[string]config.TargetMeta, len(s.cfg.Targets))
+	for target, meta := range s.cfg.Targets {
+		metas[target] = meta
+	}
+	s.configMu.RUnlock()
+
+	states := s.stateManager.GetAllStates()
+	statuses := make([]*TargetStatus, 0, len(targets))
+	for target, probeType := range targets {
+		status := &TargetStatus{
+			Target:    target,
+			ProbeType: string(probeType),
+			Tags:      metas[target].Tags,
+			Severity:  metas[target].Severity,
+			State:     TargetStateUp,
+			DependsOn: metas[target].DependsOn,
+		}
+		if status.Severity == "" {
+			status.Severity = string(webhook.SeverityCritical)
+		}
+		if state, exists := states[target]; exists {
+			status.Down = state.IsDown
+			status.FailCount = state.FailCount
+			status.SuccessCount = state.SuccessCount
+			status.FlapRate = state.FlapRate
+			status.Degraded = state.Degraded
+			status.DegradedReason = state.DegradedReason
+			status.LatencyMs = state.LastLatency.Milliseconds()
+			status.JitterMs = state.Jitter.Milliseconds()
+			status.PacketLoss = state.PacketLoss
+			switch {
+			case state.Flapping:
+				status.State = TargetStateFlapping
+			case state.IsDown && state.SuppressedBy != "":
+				status.State = TargetStateDependent
+			case state.IsDown && state.SuccessCount > 0:
+				status.State = TargetStateRecovering
+			case state.IsDown:
+				status.State = TargetStateDown
+			case state.Degraded != "":
+				status.State = TargetStateDegraded
+			}
+			status.Since = formatTime(state.LastChangeTime)
+			status.LastCheckAt = formatTime(state.LastCheckTime)
+			status.LastAlertAt = formatTime(state.LastAlertTime)
+			status.LastResult = state.LastResult
+			status.SuppressedBy = state.SuppressedBy
+			status.MutedReason = state.MutedReason
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].ProbeType != statuses[j].ProbeType {
+			return statuses[i].ProbeType < statuses[j].ProbeType
+		}
+		return statuses[i].Target < statuses[j].Target
+	})
+	return statuses
+}
+
+// formatTime 格式化时间，零值返回空字符串
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// ProbeStats 获取检测工作池统计
+func (s *Scheduler) ProbeStats() ProbePoolStats {
+	return s.pool.Stats()
+}
+
+// WebhookStats 获取告警分发统计
+func (s *Scheduler) WebhookStats() webhook.DispatchStats {
+	return s.dispatcher.Stats()
 }
@@ -1,21 +1,38 @@
 package monitor
 
 import (
+	"dnsfailover/internal/storage"
 	"sync"
 	"time"
 )
 
-// DefaultSilenceDuration 默认静默期时间（发送告警后暂停检测的时间）
-// 可通过配置覆盖
-var DefaultSilenceDuration = 60 * time.Second
+// DefaultSilenceDuration 默认静默期时间（发送故障告警后不重复告警的时间，期间照常检测）
+// 配置了 silence_period 时以配置为准
+const DefaultSilenceDuration = 60 * time.Second
 
 // DomainState 域名运行时状态（仅存在于内存中）
 type DomainState struct {
-	Domain        string    // 域名/目标
-	FailCount     int       // 当前周期内的连续失败次数
-	LastAlertTime time.Time // 最后一次告警时间
-	IsDown        bool      // 当前是否处于故障状态
-	SilenceUntil  time.Time // 静默期截止时间（此时间前不进行检测）
+	Domain         string               // 域名/目标
+	FailCount      int                  // 当前周期内的连续失败次数
+	SuccessCount   int                  // 故障后的连续成功次数（恢复中）
+	ReminderCount  int                  // 本次故障已发送的仍未恢复提醒次数
+	FirstFailTime  time.Time            // 本轮连续失败的首次失败时间
+	LastAlertTime  time.Time            // 最后一次告警时间
+	LastCheckTime  time.Time            // 最后一次检测时间
+	LastChangeTime time.Time            // 最后一次正常/故障状态切换时间
+	IsDown         bool                 // 当前是否处于故障状态
+	SilenceUntil   time.Time            // 静默期截止时间（此时间前不重复发送故障告警）
+	History        []bool               // 最近的检测结果（抖动检测窗口）
+	Flapping       bool                 // 当前是否处于抖动状态
+	FlapRate       int                  // 最近窗口内的状态变化率（%）
+	LastLatency    time.Duration        // 最近一次成功检测的延迟
+	Jitter         time.Duration        // 最近一次成功检测的抖动
+	PacketLoss     float64              // 最近一次成功检测的丢包率（%）
+	Degraded       string               // 性能降级级别 warning/critical，未降级时为空
+	DegradedReason string               // 超出阈值的说明
+	LastResult     *storage.CheckResult // 最近一次检测的结构化结果
+	SuppressedBy   string               // 故障告警被哪个上游依赖抑制，未抑制时为空
+	MutedReason    string               // 故障告警被维护窗口或静音抑制的原因，未抑制时为空
 }
 
 // StateManager 状态管理器（内存中维护域名状态）
@@ -72,14 +89,19 @@ func (sm *StateManager) IncrementFailCount(domain string) int {
 
 	if state, exists := sm.states[domain]; exists {
 		state.FailCount++
+		state.SuccessCount = 0
+		if state.FailCount == 1 {
+			state.FirstFailTime = time.Now()
+		}
 		return state.FailCount
 	}
 
 	// 如果不存在，创建新状态
 	sm.states[domain] = &DomainState{
-		Domain:    domain,
-		FailCount: 1,
-		IsDown:    false,
+		Domain:        domain,
+		FailCount:     1,
+		FirstFailTime: time.Now(),
+		IsDown:        false,
 	}
 	return 1
 }
@@ -90,21 +112,63 @@ func (sm *StateManager) ResetFailCount(domain string) {
 	defer sm.mu.Unlock()
 
 	if state, exists := sm.states[domain]; exists {
+		if state.IsDown {
+			state.LastChangeTime = time.Now()
+		}
 		state.FailCount = 0
+		state.SuccessCount = 0
+		state.ReminderCount = 0
+		state.FirstFailTime = time.Time{}
 		state.IsDown = false
+		state.SuppressedBy = ""
+		state.MutedReason = ""
 	}
 }
 
-// MarkDown 标记为故障状态，并设置静默期
-func (sm *StateManager) MarkDown(domain string) {
+// IncrementReminderCount 增加仍未恢复提醒次数
+func (sm *StateManager) IncrementReminderCount(domain string) int {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
 	if state, exists := sm.states[domain]; exists {
-		state.IsDown = true
-		state.LastAlertTime = time.Now()
-		state.SilenceUntil = time.Now().Add(DefaultSilenceDuration)
+		state.ReminderCount++
+		return state.ReminderCount
 	}
+	return 0
+}
+
+// IncrementSuccessCount 增加故障后的连续成功计数
+func (sm *StateManager) IncrementSuccessCount(domain string) int {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if state, exists := sm.states[domain]; exists {
+		state.SuccessCount++
+		return state.SuccessCount
+	}
+	return 0
+}
+
+// RecordCheck 记录一次检测的时间与结构化结果
+func (sm *StateManager) RecordCheck(domain string, result *storage.CheckResult) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if state, exists := sm.states[domain]; exists {
+		state.LastCheckTime = time.Now()
+		state.LastResult = result
+	}
+}
+
+// GetIncidentDuration 获取本轮故障已持续的时间（自首次失败起）
+func (sm *StateManager) GetIncidentDuration(domain string) time.Duration {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if state, exists := sm.states[domain]; exists && !state.FirstFailTime.IsZero() {
+		return time.Since(state.FirstFailTime)
+	}
+	return 0
 }
 
 // MarkDownWithSilence 标记为故障状态，指定静默期
@@ -113,12 +177,41 @@ func (sm *StateManager) MarkDownWithSilence(domain string, silenceDuration time.
 	defer sm.mu.Unlock()
 
 	if state, exists := sm.states[domain]; exists {
+		if !state.IsDown {
+			state.LastChangeTime = time.Now()
+		}
 		state.IsDown = true
+		state.Degraded = ""
+		state.DegradedReason = ""
+		state.SuppressedBy = ""
+		state.MutedReason = ""
 		state.LastAlertTime = time.Now()
 		state.SilenceUntil = time.Now().Add(silenceDuration)
 	}
 }
 
+// MarkDownMuted 标记为故障状态但不记为已告警（维护窗口或静音期间）
+// 返回抑制原因是否变化，用于只在首次抑制时记录日志
+func (sm *StateManager) MarkDownMuted(domain, reason string) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	state, exists := sm.states[domain]
+	if !exists {
+		return false
+	}
+	if !state.IsDown {
+		state.LastChangeTime = time.Now()
+	}
+	state.IsDown = true
+	state.Degraded = ""
+	state.DegradedReason = ""
+	state.SuppressedBy = ""
+	changed := state.MutedReason != reason
+	state.MutedReason = reason
+	return changed
+}
+
 // IsSilenced 检查目标是否在静默期内
 func (sm *StateManager) IsSilenced(domain string) bool {
 	sm.mu.RLock()
@@ -170,10 +263,25 @@ func (sm *StateManager) GetAllStates() map[string]*DomainState {
 	result := make(map[string]*DomainState)
 	for k, v := range sm.states {
 		result[k] = &DomainState{
-			Domain:        v.Domain,
-			FailCount:     v.FailCount,
-			LastAlertTime: v.LastAlertTime,
-			IsDown:        v.IsDown,
+			Domain:         v.Domain,
+			FailCount:      v.FailCount,
+			SuccessCount:   v.SuccessCount,
+			FirstFailTime:  v.FirstFailTime,
+			LastAlertTime:  v.LastAlertTime,
+			LastCheckTime:  v.LastCheckTime,
+			LastChangeTime: v.LastChangeTime,
+			IsDown:         v.IsDown,
+			SilenceUntil:   v.SilenceUntil,
+			Flapping:       v.Flapping,
+			FlapRate:       v.FlapRate,
+			LastLatency:    v.LastLatency,
+			Jitter:         v.Jitter,
+			PacketLoss:     v.PacketLoss,
+			Degraded:       v.Degraded,
+			DegradedReason: v.DegradedReason,
+			LastResult:     v.LastResult,
+			SuppressedBy:   v.SuppressedBy,
+			MutedReason:    v.MutedReason,
 		}
 	}
 	return result
@@ -0,0 +1,63 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// ErrorCategory 检测错误分类
+type ErrorCategory string
+
+const (
+	ErrorTimeout   ErrorCategory = "timeout"   // 超时（含 ICMP 无应答）
+	ErrorRefused   ErrorCategory = "refused"   // 连接被拒绝
+	ErrorDNS       ErrorCategory = "dns"       // 域名解析失败
+	ErrorTLS       ErrorCategory = "tls"       // TLS 握手或证书错误
+	ErrorAssertion ErrorCategory = "assertion" // 响应不符合预期（如 HTTP 状态码）
+	ErrorCanceled  ErrorCategory = "canceled"  // 检测被取消（停止服务或配置变更）
+	ErrorOther     ErrorCategory = "other"     // 其他错误
+)
+
+// Classify 按错误链判断错误分类，err 为 nil 时返回空
+// 超时无应答、断言失败等不对应具体错误类型的情况由检测器直接设置分类
+func Classify(err error) ErrorCategory {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ErrorCanceled
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorDNS
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorTimeout
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorRefused
+	}
+
+	var (
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	if errors.As(err, &recordErr) || errors.As(err, &alertErr) || errors.As(err, &verifyErr) ||
+		errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return ErrorTLS
+	}
+	return ErrorOther
+}
@@ -1,16 +1,25 @@
 package probe
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
+	"math"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
 	"time"
 )
 
+// maxBodyBytes 读取响应体的上限
+const maxBodyBytes = 1 << 20
+
 // HTTPChecker HTTP检测器
 type HTTPChecker struct {
-	client *http.Client
+	client  *http.Client
+	timeout time.Duration // 默认超时时间（调用时未指定超时时使用）
 }
 
 // NewHTTPChecker 创建HTTP检测器
@@ -23,8 +32,8 @@ func NewHTTPChecker(timeout time.Duration) *HTTPChecker {
 	}
 
 	return &HTTPChecker{
+		timeout: timeout,
 		client: &http.Client{
-			Timeout:   timeout,
 			Transport: transport,
 			// 不跟随重定向
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
@@ -41,58 +50,103 @@ func (c *HTTPChecker) Type() ProbeType {
 
 // Check 执行HTTP检测
 // target 格式: URL (例如: http://example.com/health 或 https://example.com:8443/ping)
-func (c *HTTPChecker) Check(target string, timeout time.Duration) *Result {
+func (c *HTTPChecker) Check(ctx context.Context, target string, timeout time.Duration) *Result {
 	result := &Result{
-		Type:   TypeHTTP,
-		Target: target,
+		Type:      TypeHTTP,
+		Target:    target,
+		CheckedAt: time.Now(),
 	}
+	defer func() { result.Timings.Total = time.Since(result.CheckedAt) }()
 
 	// 验证URL格式
 	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
-		result.Error = fmt.Errorf("无效的URL格式 (应以 http:// 或 https:// 开头): %s", target)
-		return result
+		return result.fail(fmt.Errorf("无效的URL格式 (应以 http:// 或 https:// 开头): %s", target))
 	}
 
-	// 更新超时时间
-	c.client.Timeout = timeout
+	// 超时通过 ctx 控制，并发检测之间互不影响
+	if timeout <= 0 {
+		timeout = c.timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// 记录各阶段耗时
+	var dnsStart, connectStart, tlsStart time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:  func(httptrace.DNSDoneInfo) { result.Timings.DNS = time.Since(dnsStart) },
+		ConnectStart: func(_, _ string) {
+			connectStart = time.Now()
+		},
+		ConnectDone: func(_, addr string, _ error) {
+			result.Timings.Connect = time.Since(connectStart)
+			if host, _, splitErr := net.SplitHostPort(addr); splitErr == nil {
+				result.ResolvedIP = host
+			}
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { result.Timings.TLS = time.Since(tlsStart) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			// 复用连接时没有解析、建连与握手阶段
+			if info.Reused {
+				result.setMetric("conn_reused", 1)
+			}
+		},
+		GotFirstResponseByte: func() { result.Timings.TTFB = time.Since(result.CheckedAt) },
+	}
 
-	// 记录开始时间
-	start := time.Now()
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, target, nil)
+	if err != nil {
+		return result.fail(fmt.Errorf("HTTP请求失败: %w", err))
+	}
 
 	// 发送GET请求
-	resp, err := c.client.Get(target)
+	resp, err := c.client.Do(req)
 	if err != nil {
-		result.Error = fmt.Errorf("HTTP请求失败: %w", err)
-		return result
+		return result.fail(fmt.Errorf("HTTP请求失败: %w", err))
 	}
 	defer resp.Body.Close()
 
-	// 计算延迟
-	result.Latency = time.Since(start)
+	// 计算延迟（收到响应头为止）
+	result.Latency = time.Since(result.CheckedAt)
+	result.StatusCode = resp.StatusCode
+
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		days := time.Until(resp.TLS.PeerCertificates[0].NotAfter).Hours() / 24
+		result.setMetric("cert_expiry_days", math.Floor(days))
+	}
+
+	// 读取响应体（最多 maxBodyBytes），只计入总耗时；读取超时或连接被重置时检测失败
+	n, err := io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodyBytes))
+	result.setMetric("response_bytes", float64(n))
+	if err != nil {
+		return result.fail(fmt.Errorf("读取HTTP响应体失败: %w", err))
+	}
 
 	// 检查状态码 (2xx 和 3xx 都认为成功)
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		result.Success = true
-	} else {
-		result.Error = fmt.Errorf("HTTP状态码异常: %d", resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		result.fail(fmt.Errorf("HTTP状态码异常: %d", resp.StatusCode))
+		result.ErrorCategory = ErrorAssertion
+		return result
 	}
 
+	result.Success = true
 	return result
 }
 
 // CheckWithRetry 带重试的HTTP检测
-func (c *HTTPChecker) CheckWithRetry(target string, timeout time.Duration, retryCount int) *Result {
+func (c *HTTPChecker) CheckWithRetry(ctx context.Context, target string, timeout time.Duration, retryCount int) *Result {
 	var result *Result
 
 	for i := 0; i < retryCount; i++ {
-		result = c.Check(target, timeout)
+		result = c.Check(ctx, target, timeout)
 		if result.Success {
 			return result
 		}
 
 		// 如果不是最后一次重试，等待一小段时间
-		if i < retryCount-1 {
-			time.Sleep(time.Second)
+		if i < retryCount-1 && !waitRetry(ctx, time.Second) {
+			break
 		}
 	}
 
@@ -1,15 +1,42 @@
 package probe
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	goping "github.com/go-ping/ping"
 )
 
+// PingOptions Ping 检测参数
+type PingOptions struct {
+	Count         int           // 发送包数
+	Interval      time.Duration // 发包间隔
+	Size          int           // 负载大小（字节）
+	TTL           int
+	LossThreshold float64 // 丢包率达到该值（%）判定失败
+}
+
+// DefaultPingOptions 默认 Ping 检测参数
+func DefaultPingOptions() PingOptions {
+	return PingOptions{
+		Count:         4,
+		Interval:      300 * time.Millisecond,
+		Size:          24,
+		TTL:           64,
+		LossThreshold: 100,
+	}
+}
+
 // PingChecker ICMP Ping检测器
-type PingChecker struct{}
+// 默认使用原始套接字（特权模式），无权限时自动改用非特权 UDP 模式
+type PingChecker struct {
+	unprivileged atomic.Bool
+}
 
 // NewPingChecker 创建Ping检测器
 func NewPingChecker() *PingChecker {
@@ -21,79 +48,137 @@ func (c *PingChecker) Type() ProbeType {
 	return TypePing
 }
 
-// Check 执行ICMP Ping检测
-func (c *PingChecker) Check(target string, timeout time.Duration) *Result {
+// Check 使用默认参数执行ICMP Ping检测
+func (c *PingChecker) Check(ctx context.Context, target string, timeout time.Duration) *Result {
+	return c.CheckWithOptions(ctx, target, timeout, DefaultPingOptions())
+}
+
+// Unprivileged 是否已切换为非特权 UDP 模式
+func (c *PingChecker) Unprivileged() bool {
+	return c.unprivileged.Load()
+}
+
+// CheckWithOptions 按指定参数执行ICMP Ping检测，ctx 取消时立即停止发包
+func (c *PingChecker) CheckWithOptions(ctx context.Context, target string, timeout time.Duration, opts PingOptions) *Result {
 	result := &Result{
-		Type:   TypePing,
-		Target: target,
+		Type:      TypePing,
+		Target:    target,
+		CheckedAt: time.Now(),
 	}
+	defer func() { result.Timings.Total = time.Since(result.CheckedAt) }()
 
 	// 如果是域名，先使用系统 DNS 解析
 	ipAddr := target
 	if net.ParseIP(target) == nil {
 		// 是域名，需要解析
-		ips, err := net.LookupHost(target)
+		dnsCtx, cancel := context.WithTimeout(ctx, timeout)
+		dnsStart := time.Now()
+		ips, err := net.DefaultResolver.LookupHost(dnsCtx, target)
+		result.Timings.DNS = time.Since(dnsStart)
+		cancel()
 		if err != nil {
-			result.Error = fmt.Errorf("DNS解析失败 (%s): %w", target, err)
-			return result
+			return result.fail(fmt.Errorf("DNS解析失败 (%s): %w", target, err))
 		}
 		if len(ips) == 0 {
-			result.Error = fmt.Errorf("DNS解析未返回IP地址: %s", target)
-			return result
+			return result.fail(fmt.Errorf("DNS解析未返回IP地址: %s", target))
 		}
 		ipAddr = ips[0] // 使用第一个IP
 	}
-
-	pinger, err := goping.NewPinger(ipAddr)
-	if err != nil {
-		result.Error = fmt.Errorf("创建pinger失败: %w", err)
-		return result
+	result.ResolvedIP = ipAddr
+
+	pinger, err := c.run(ctx, ipAddr, timeout, opts, !c.unprivileged.Load())
+	if err != nil && pinger != nil && pinger.Privileged() && isPermissionError(err) {
+		// 没有原始套接字权限（未以 root 运行或缺少 CAP_NET_RAW），改用非特权 UDP 模式，
+		// 之后的检测直接使用非特权模式
+		c.unprivileged.Store(true)
+		pinger, err = c.run(ctx, ipAddr, timeout, opts, false)
 	}
-
-	// Linux系统使用特权模式（ICMP）
-	pinger.SetPrivileged(true)
-
-	// 设置ping参数
-	pinger.Count = 4                         // 发送4个包
-	pinger.Timeout = timeout                 // 超时时间
-	pinger.Interval = time.Millisecond * 300 // 包间隔300ms
-
-	// 执行ping
-	err = pinger.Run()
 	if err != nil {
-		result.Error = fmt.Errorf("执行ping失败: %w", err)
-		return result
+		if pinger == nil {
+			return result.fail(err)
+		}
+		if !pinger.Privileged() && isPermissionError(err) {
+			return result.fail(fmt.Errorf("执行ping失败（非特权模式需将 net.ipv4.ping_group_range 设置为包含当前用户组）: %w", err))
+		}
+		return result.fail(fmt.Errorf("执行ping失败: %w", err))
+	}
+	if ctx.Err() != nil {
+		return result.fail(fmt.Errorf("检测已取消: %w", ctx.Err()))
 	}
 
 	// 获取统计信息
 	stats := pinger.Statistics()
+	result.PacketLoss = stats.PacketLoss
+	result.setMetric("packets_sent", float64(stats.PacketsSent))
+	result.setMetric("packets_recv", float64(stats.PacketsRecv))
 
 	// 判断是否成功（至少有一个包收到响应）
-	if stats.PacketsRecv > 0 {
-		result.Success = true
-		result.Latency = stats.AvgRtt
-	} else {
-		result.Success = false
-		result.Error = fmt.Errorf("ICMP应答超时 (发送: %d, 接收: %d, 丢包率: %.0f%%)",
-			stats.PacketsSent, stats.PacketsRecv, stats.PacketLoss)
+	if stats.PacketsRecv == 0 {
+		result.fail(fmt.Errorf("ICMP应答超时 (发送: %d, 接收: %d, 丢包率: %.0f%%)",
+			stats.PacketsSent, stats.PacketsRecv, stats.PacketLoss))
+		result.ErrorCategory = ErrorTimeout
+		return result
+	}
+
+	result.Latency = stats.AvgRtt
+	result.Jitter = stats.StdDevRtt
+	result.RTT = &RTTStats{
+		Min:    stats.MinRtt,
+		Avg:    stats.AvgRtt,
+		Max:    stats.MaxRtt,
+		StdDev: stats.StdDevRtt,
+	}
+
+	// 丢包率达到阈值判定失败
+	if opts.LossThreshold < 100 && stats.PacketLoss >= opts.LossThreshold {
+		result.fail(fmt.Errorf("丢包率过高 (发送: %d, 接收: %d, 丢包率: %.0f%%, 阈值: %.0f%%)",
+			stats.PacketsSent, stats.PacketsRecv, stats.PacketLoss, opts.LossThreshold))
+		result.ErrorCategory = ErrorAssertion
+		return result
 	}
 
+	result.Success = true
 	return result
 }
 
+// run 创建 pinger 并执行，返回执行完成的 pinger
+func (c *PingChecker) run(ctx context.Context, ipAddr string, timeout time.Duration, opts PingOptions, privileged bool) (*goping.Pinger, error) {
+	pinger, err := goping.NewPinger(ipAddr)
+	if err != nil {
+		return nil, fmt.Errorf("创建pinger失败: %w", err)
+	}
+
+	pinger.SetPrivileged(privileged)
+	pinger.Count = opts.Count
+	pinger.Interval = opts.Interval
+	pinger.Size = opts.Size
+	pinger.TTL = opts.TTL
+	pinger.Timeout = timeout
+
+	// 执行ping（ctx 取消时停止）
+	stop := context.AfterFunc(ctx, pinger.Stop)
+	defer stop()
+	return pinger, pinger.Run()
+}
+
+// isPermissionError 判断是否为创建套接字时的权限错误
+func isPermissionError(err error) bool {
+	return errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EACCES)
+}
+
 // CheckWithRetry 带重试的Ping检测
-func (c *PingChecker) CheckWithRetry(target string, timeout time.Duration, retryCount int) *Result {
+func (c *PingChecker) CheckWithRetry(ctx context.Context, target string, timeout time.Duration, retryCount int) *Result {
 	var result *Result
 
 	for i := 0; i < retryCount; i++ {
-		result = c.Check(target, timeout)
+		result = c.Check(ctx, target, timeout)
 		if result.Success {
 			return result
 		}
 
 		// 如果不是最后一次重试，等待一小段时间
-		if i < retryCount-1 {
-			time.Sleep(time.Second)
+		if i < retryCount-1 && !waitRetry(ctx, time.Second) {
+			break
 		}
 	}
 
@@ -1,6 +1,9 @@
 package probe
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // ProbeType 检测类型
 type ProbeType string
@@ -13,17 +16,71 @@ const (
 
 // Result 通用检测结果
 type Result struct {
-	Type    ProbeType     // 检测类型
-	Target  string        // 检测目标
-	Success bool          // 是否成功
-	Latency time.Duration // 延迟
-	Error   error         // 错误信息
+	Type          ProbeType          // 检测类型
+	Target        string             // 检测目标
+	Success       bool               // 是否成功
+	Latency       time.Duration      // 延迟（Ping 为平均往返时间，TCP 为解析与建连总耗时，HTTP 为收到响应头的耗时）
+	PacketLoss    float64            // 丢包率（%，仅 Ping）
+	Jitter        time.Duration      // 抖动（仅 Ping，为往返时间标准差）
+	ResolvedIP    string             // 实际检测的 IP 地址
+	StatusCode    int                // HTTP 状态码（仅 HTTP）
+	Timings       Timings            // 各阶段耗时
+	RTT           *RTTStats          // 往返时间统计（仅 Ping）
+	Error         error              // 错误信息
+	ErrorCategory ErrorCategory      // 错误分类
+	Metrics       map[string]float64 // 其他指标，如 Ping 收发包数、HTTP 响应字节数
+	CheckedAt     time.Time          // 检测开始时间
+}
+
+// Timings 检测各阶段耗时（未经历的阶段为 0）
+type Timings struct {
+	DNS     time.Duration // DNS 解析
+	Connect time.Duration // TCP 建连
+	TLS     time.Duration // TLS 握手
+	TTFB    time.Duration // 自开始到收到首字节
+	Total   time.Duration // 总耗时
+}
+
+// RTTStats Ping 往返时间统计
+type RTTStats struct {
+	Min    time.Duration
+	Avg    time.Duration
+	Max    time.Duration
+	StdDev time.Duration
 }
 
 // Checker 检测器接口
 type Checker interface {
-	// Check 执行检测
-	Check(target string, timeout time.Duration) *Result
+	// Check 执行检测，ctx 取消时立即中止
+	Check(ctx context.Context, target string, timeout time.Duration) *Result
 	// Type 返回检测类型
 	Type() ProbeType
 }
+
+// fail 记录错误并按错误类型分类
+func (r *Result) fail(err error) *Result {
+	r.Success = false
+	r.Error = err
+	r.ErrorCategory = Classify(err)
+	return r
+}
+
+// waitRetry 重试前等待一段时间，ctx 取消时返回 false
+func waitRetry(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// setMetric 记录一项指标
+func (r *Result) setMetric(name string, value float64) {
+	if r.Metrics == nil {
+		r.Metrics = make(map[string]float64)
+	}
+	r.Metrics[name] = value
+}
@@ -1,6 +1,7 @@
 package probe
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"time"
@@ -21,55 +22,75 @@ func (c *TCPChecker) Type() ProbeType {
 
 // Check 执行TCP端口检测
 // target 格式: host:port (例如: example.com:443)
-func (c *TCPChecker) Check(target string, timeout time.Duration) *Result {
+// 域名解析出多个地址时依次尝试，直到连接成功或超时
+func (c *TCPChecker) Check(ctx context.Context, target string, timeout time.Duration) *Result {
 	result := &Result{
-		Type:   TypeTCP,
-		Target: target,
+		Type:      TypeTCP,
+		Target:    target,
+		CheckedAt: time.Now(),
 	}
+	defer func() { result.Timings.Total = time.Since(result.CheckedAt) }()
 
 	// 验证目标格式
 	host, port, err := net.SplitHostPort(target)
 	if err != nil {
-		result.Error = fmt.Errorf("无效的目标格式 (应为 host:port): %w", err)
-		return result
+		return result.fail(fmt.Errorf("无效的目标格式 (应为 host:port): %w", err))
 	}
 
 	if host == "" || port == "" {
-		result.Error = fmt.Errorf("无效的目标格式: host=%s, port=%s", host, port)
-		return result
+		return result.fail(fmt.Errorf("无效的目标格式: host=%s, port=%s", host, port))
 	}
 
-	// 记录开始时间
-	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// 解析域名
+	ips := []string{host}
+	if net.ParseIP(host) == nil {
+		dnsStart := time.Now()
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		result.Timings.DNS = time.Since(dnsStart)
+		if err != nil {
+			return result.fail(fmt.Errorf("DNS解析失败 (%s): %w", host, err))
+		}
+		ips = addrs
+	}
 
 	// 尝试建立TCP连接
-	conn, err := net.DialTimeout("tcp", target, timeout)
-	if err != nil {
-		result.Error = fmt.Errorf("TCP连接失败: %w", err)
+	var dialer net.Dialer
+	for _, ip := range ips {
+		result.ResolvedIP = ip
+		connectStart := time.Now()
+		conn, dialErr := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, port))
+		result.Timings.Connect = time.Since(connectStart)
+		if dialErr != nil {
+			err = dialErr
+			continue
+		}
+		conn.Close()
+
+		// 计算延迟
+		result.Success = true
+		result.Latency = time.Since(result.CheckedAt)
 		return result
 	}
-	defer conn.Close()
 
-	// 计算延迟
-	result.Latency = time.Since(start)
-	result.Success = true
-
-	return result
+	return result.fail(fmt.Errorf("TCP连接失败: %w", err))
 }
 
 // CheckWithRetry 带重试的TCP检测
-func (c *TCPChecker) CheckWithRetry(target string, timeout time.Duration, retryCount int) *Result {
+func (c *TCPChecker) CheckWithRetry(ctx context.Context, target string, timeout time.Duration, retryCount int) *Result {
 	var result *Result
 
 	for i := 0; i < retryCount; i++ {
-		result = c.Check(target, timeout)
+		result = c.Check(ctx, target, timeout)
 		if result.Success {
 			return result
 		}
 
 		// 如果不是最后一次重试，等待一小段时间
-		if i < retryCount-1 {
-			time.Sleep(time.Second)
+		if i < retryCount-1 && !waitRetry(ctx, time.Second) {
+			break
 		}
 	}
 
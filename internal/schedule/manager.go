@@ -2,8 +2,12 @@ package schedule
 
 import (
 	"bytes"
+	"context"
 	"dnsfailover/internal/logger"
+	"dnsfailover/internal/maintenance"
 	"dnsfailover/internal/probe"
+	"dnsfailover/internal/signature"
+	"dnsfailover/internal/storage"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -21,6 +25,10 @@ type Manager struct {
 	mu        sync.RWMutex
 	isRunning bool
 
+	// 停止调度器时取消，中止进行中的检测
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	// 检测器
 	pingChecker *probe.PingChecker
 	tcpChecker  *probe.TCPChecker
@@ -32,7 +40,10 @@ type Manager struct {
 
 // NewManager 创建定时任务管理器
 func NewManager() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Manager{
+		ctx:         ctx,
+		cancel:      cancel,
 		cron:        cron.New(cron.WithSeconds()), // 支持秒级精度
 		tasks:       make(map[string]*Task),
 		cronIDs:     make(map[string]cron.EntryID),
@@ -56,22 +67,27 @@ func (m *Manager) Start() {
 		return
 	}
 
+	if m.ctx.Err() != nil {
+		m.ctx, m.cancel = context.WithCancel(context.Background())
+	}
 	m.cron.Start()
 	m.isRunning = true
 	logger.Info("[Schedule] 定时任务调度器已启动")
 }
 
-// Stop 停止调度器
+// Stop 停止调度器，取消进行中的检测并等待任务退出
 func (m *Manager) Stop() {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	if !m.isRunning {
+		m.mu.Unlock()
 		return
 	}
-
-	m.cron.Stop()
+	m.cancel()
 	m.isRunning = false
+	m.mu.Unlock()
+
+	// 不持有锁等待，任务退出前仍需读取任务信息
+	<-m.cron.Stop().Done()
 	logger.Info("[Schedule] 定时任务调度器已停止")
 }
 
@@ -127,6 +143,49 @@ func (m *Manager) RemoveTask(taskID string) error {
 	return nil
 }
 
+// SyncTasks 将调度器中的任务与给定任务列表对齐
+// 新增或定义有变化的任务重新加载，不在列表中的任务被移除
+func (m *Manager) SyncTasks(tasks []*Task) {
+	m.mu.RLock()
+	current := make(map[string]*Task, len(m.tasks))
+	for id, task := range m.tasks {
+		current[id] = task
+	}
+	m.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		wanted[task.ID] = true
+		if old, exists := current[task.ID]; exists && sameDefinition(old, task) {
+			continue
+		}
+		if err := m.AddTask(task); err != nil {
+			logger.Warnf("[Schedule] 加载任务失败 [%s]: %v", task.Name, err)
+		}
+	}
+
+	for id := range current {
+		if !wanted[id] {
+			m.RemoveTask(id)
+		}
+	}
+}
+
+// sameDefinition 判断两个任务的定义是否一致（忽略执行状态）
+func sameDefinition(a, b *Task) bool {
+	if a.Name != b.Name || a.Enabled != b.Enabled || a.Cron != b.Cron || a.CheckType != b.CheckType ||
+		a.Target != b.Target || a.Port != b.Port || a.Timeout != b.Timeout || a.WebhookURL != b.WebhookURL ||
+		a.WebhookSecret != b.WebhookSecret || len(a.WebhookData) != len(b.WebhookData) {
+		return false
+	}
+	for k, v := range a.WebhookData {
+		if bv, ok := b.WebhookData[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
 // GetTask 获取任务
 func (m *Manager) GetTask(taskID string) (*Task, bool) {
 	m.mu.RLock()
@@ -210,6 +269,7 @@ func (m *Manager) DisableTask(taskID string) error {
 func (m *Manager) executeTask(taskID string) {
 	m.mu.RLock()
 	task, exists := m.tasks[taskID]
+	ctx := m.ctx
 	m.mu.RUnlock()
 
 	if !exists || !task.Enabled {
@@ -218,8 +278,12 @@ func (m *Manager) executeTask(taskID string) {
 
 	logger.Infof("[Schedule] 开始执行任务: %s (%s)", task.Name, taskID)
 
-	// 执行检测
-	available, message := m.checkTarget(task)
+	// 执行检测（调度器停止时取消，不记录结果）
+	available, message := m.checkTarget(ctx, task)
+	if ctx.Err() != nil {
+		logger.Infof("[Schedule] 任务已取消: %s", task.Name)
+		return
+	}
 
 	now := time.Now()
 	result := &TaskResult{
@@ -242,11 +306,8 @@ func (m *Manager) executeTask(taskID string) {
 	}
 	m.mu.Unlock()
 
-	// 发送 Webhook 通知
-	if task.WebhookURL != "" {
-		webhookSent := m.sendWebhook(task, available, message, now)
-		result.WebhookSent = webhookSent
-	}
+	// 发送 Webhook 通知（目标处于维护窗口时不触发切换等操作）
+	m.notify(task, result)
 
 	// 回调更新（持久化）
 	if m.onTaskUpdate != nil {
@@ -262,8 +323,35 @@ func (m *Manager) executeTask(taskID string) {
 	}
 }
 
+// notify 发送任务 Webhook，目标处于维护窗口时跳过并记录在任务结果中
+func (m *Manager) notify(task *Task, result *TaskResult) {
+	if task.WebhookURL == "" {
+		return
+	}
+	if mw := maintenanceFor(task); mw != nil {
+		logger.Infof("[Schedule] 任务 %s 的目标处于维护窗口「%s」，跳过 Webhook", task.Name, mw.Name)
+		result.SkipReason = fmt.Sprintf("维护窗口「%s」中，未触发 Webhook", mw.Name)
+		m.mu.Lock()
+		task.LastResult += "（" + result.SkipReason + "）"
+		m.mu.Unlock()
+		return
+	}
+	result.WebhookSent = m.sendWebhook(task, result.Success, result.Message, result.ExecutedAt)
+}
+
+// maintenanceFor 返回任务目标当前所处的维护窗口（TCP 任务同时匹配 host:port 形式）
+func maintenanceFor(task *Task) *storage.MaintenanceWindow {
+	if mw := maintenance.ActiveFor(task.Target, nil); mw != nil {
+		return mw
+	}
+	if task.CheckType == CheckTypeTCP && task.Port > 0 {
+		return maintenance.ActiveFor(fmt.Sprintf("%s:%d", task.Target, task.Port), nil)
+	}
+	return nil
+}
+
 // checkTarget 检测目标
-func (m *Manager) checkTarget(task *Task) (bool, string) {
+func (m *Manager) checkTarget(ctx context.Context, task *Task) (bool, string) {
 	timeout := time.Duration(task.Timeout) * time.Second
 	if timeout == 0 {
 		timeout = 5 * time.Second
@@ -271,7 +359,7 @@ func (m *Manager) checkTarget(task *Task) (bool, string) {
 
 	switch task.CheckType {
 	case CheckTypePing:
-		result := m.pingChecker.Check(task.Target, timeout)
+		result := m.pingChecker.Check(ctx, task.Target, timeout)
 		if result.Success {
 			return true, fmt.Sprintf("Ping 成功, 延迟: %v", result.Latency)
 		}
@@ -282,14 +370,14 @@ func (m *Manager) checkTarget(task *Task) (bool, string) {
 		if task.Port > 0 {
 			target = fmt.Sprintf("%s:%d", task.Target, task.Port)
 		}
-		result := m.tcpChecker.Check(target, timeout)
+		result := m.tcpChecker.Check(ctx, target, timeout)
 		if result.Success {
 			return true, fmt.Sprintf("TCP 连接成功, 耗时: %v", result.Latency)
 		}
 		return false, result.Error.Error()
 
 	case CheckTypeHTTP:
-		result := m.httpChecker.Check(task.Target, timeout)
+		result := m.httpChecker.Check(ctx, task.Target, timeout)
 		if result.Success {
 			return true, fmt.Sprintf("HTTP 检测成功, 耗时: %v", result.Latency)
 		}
@@ -321,8 +409,22 @@ func (m *Manager) sendWebhook(task *Task, available bool, message string, execut
 		return false
 	}
 
+	req, err := http.NewRequest("POST", task.WebhookURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		logger.Errorf("[Schedule] 创建 Webhook 请求失败: %v", err)
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// 配置了签名密钥时附带 HMAC 签名，供接收方校验来源
+	if task.WebhookSecret != "" {
+		timestamp, sig := signature.Sign(task.WebhookSecret, jsonData, time.Now())
+		req.Header.Set(signature.HeaderTimestamp, timestamp)
+		req.Header.Set(signature.HeaderSignature, sig)
+	}
+
 	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Post(task.WebhookURL, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := client.Do(req)
 	if err != nil {
 		logger.Errorf("[Schedule] 发送 Webhook 失败: %v", err)
 		return false
@@ -339,9 +441,11 @@ func (m *Manager) sendWebhook(task *Task, available bool, message string, execut
 }
 
 // RunTaskNow 立即执行任务（手动触发）
-func (m *Manager) RunTaskNow(taskID string) (*TaskResult, error) {
+// ctx 取消（如 API 请求断开）或调度器停止时中止检测，不记录结果
+func (m *Manager) RunTaskNow(ctx context.Context, taskID string) (*TaskResult, error) {
 	m.mu.RLock()
 	task, exists := m.tasks[taskID]
+	managerCtx := m.ctx
 	m.mu.RUnlock()
 
 	if !exists {
@@ -350,8 +454,16 @@ func (m *Manager) RunTaskNow(taskID string) (*TaskResult, error) {
 
 	logger.Infof("[Schedule] 手动执行任务: %s", task.Name)
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(managerCtx, cancel)
+	defer stop()
+
 	// 执行检测
-	available, message := m.checkTarget(task)
+	available, message := m.checkTarget(ctx, task)
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("任务执行已取消: %s", task.Name)
+	}
 
 	now := time.Now()
 	result := &TaskResult{
@@ -374,10 +486,8 @@ func (m *Manager) RunTaskNow(taskID string) (*TaskResult, error) {
 	}
 	m.mu.Unlock()
 
-	// 发送 Webhook
-	if task.WebhookURL != "" {
-		result.WebhookSent = m.sendWebhook(task, available, message, now)
-	}
+	// 发送 Webhook（与定时执行相同，目标处于维护窗口时不触发）
+	m.notify(task, result)
 
 	// 回调更新
 	if m.onTaskUpdate != nil {
@@ -15,20 +15,21 @@ const (
 
 // Task 定时任务结构
 type Task struct {
-	ID          string            `json:"id"`           // 任务ID
-	Name        string            `json:"name"`         // 任务名称
-	Enabled     bool              `json:"enabled"`      // 是否启用
-	Cron        string            `json:"cron"`         // Cron 表达式 (如: "0 18 * * *" 每天18点)
-	CheckType   CheckType         `json:"check_type"`   // 检测类型: ping/tcp/http
-	Target      string            `json:"target"`       // 检测目标 (域名/IP/URL)
-	Port        int               `json:"port"`         // TCP检测端口 (仅 tcp 类型使用)
-	Timeout     int               `json:"timeout"`      // 超时时间(秒)
-	WebhookURL  string            `json:"webhook_url"`  // Webhook 回调地址
-	WebhookData map[string]string `json:"webhook_data"` // Webhook 自定义数据 (会附加到通知中)
-	CreatedAt   time.Time         `json:"created_at"`   // 创建时间
-	UpdatedAt   time.Time         `json:"updated_at"`   // 更新时间
-	LastRunAt   *time.Time        `json:"last_run_at"`  // 上次执行时间
-	LastResult  string            `json:"last_result"`  // 上次执行结果
+	ID            string            `json:"id"`             // 任务ID
+	Name          string            `json:"name"`           // 任务名称
+	Enabled       bool              `json:"enabled"`        // 是否启用
+	Cron          string            `json:"cron"`           // Cron 表达式 (如: "0 18 * * *" 每天18点)
+	CheckType     CheckType         `json:"check_type"`     // 检测类型: ping/tcp/http
+	Target        string            `json:"target"`         // 检测目标 (域名/IP/URL)
+	Port          int               `json:"port"`           // TCP检测端口 (仅 tcp 类型使用)
+	Timeout       int               `json:"timeout"`        // 超时时间(秒)
+	WebhookURL    string            `json:"webhook_url"`    // Webhook 回调地址
+	WebhookData   map[string]string `json:"webhook_data"`   // Webhook 自定义数据 (会附加到通知中)
+	WebhookSecret string            `json:"webhook_secret"` // Webhook 签名密钥 (设置后附带 X-Signature 签名头)
+	CreatedAt     time.Time         `json:"created_at"`     // 创建时间
+	UpdatedAt     time.Time         `json:"updated_at"`     // 更新时间
+	LastRunAt     *time.Time        `json:"last_run_at"`    // 上次执行时间
+	LastResult    string            `json:"last_result"`    // 上次执行结果
 }
 
 // TaskResult 任务执行结果
@@ -41,6 +42,7 @@ type TaskResult struct {
 	Message     string    `json:"message"`
 	ExecutedAt  time.Time `json:"executed_at"`
 	WebhookSent bool      `json:"webhook_sent"`
+	SkipReason  string    `json:"skip_reason,omitempty"` // 未触发 Webhook 的原因（如目标处于维护窗口）
 }
 
 // WebhookPayload 定时任务 Webhook 通知内容
@@ -0,0 +1,76 @@
+// Package signature 出站 Webhook 的 HMAC-SHA256 签名与校验
+//
+// 发送方在请求头中附带:
+//
+//	X-Timestamp: Unix 时间戳（秒）
+//	X-Signature: sha256=<hex(HMAC-SHA256(secret, timestamp + "." + body))>
+//
+// 接收方使用相同的共享密钥调用 Verify 校验请求来源，并通过时间窗口拒绝重放。
+// 本包仅依赖标准库，可直接复制到接收服务中使用。
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HeaderSignature 签名请求头
+	HeaderSignature = "X-Signature"
+	// HeaderTimestamp 时间戳请求头
+	HeaderTimestamp = "X-Timestamp"
+
+	signaturePrefix = "sha256="
+)
+
+// DefaultTolerance 默认允许的时间偏差
+const DefaultTolerance = 5 * time.Minute
+
+// Sign 计算签名，返回 X-Timestamp 与 X-Signature 的值
+func Sign(secret string, body []byte, now time.Time) (timestamp, sig string) {
+	timestamp = strconv.FormatInt(now.Unix(), 10)
+	return timestamp, signaturePrefix + compute(secret, timestamp, body)
+}
+
+// Verify 校验签名与时间戳
+// tolerance 为允许的时间偏差（<= 0 时使用 DefaultTolerance），超出视为重放
+func Verify(secret, timestamp, sig string, body []byte, tolerance time.Duration) error {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("无效的时间戳: %q", timestamp)
+	}
+	skew := time.Since(time.Unix(ts, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > tolerance {
+		return fmt.Errorf("时间戳超出允许范围: 偏差 %v", skew.Round(time.Second))
+	}
+
+	if !strings.HasPrefix(sig, signaturePrefix) {
+		return fmt.Errorf("无效的签名格式")
+	}
+	expected := compute(secret, timestamp, body)
+	if !hmac.Equal([]byte(strings.TrimPrefix(sig, signaturePrefix)), []byte(expected)) {
+		return fmt.Errorf("签名不匹配")
+	}
+	return nil
+}
+
+// compute 计算 hex(HMAC-SHA256(secret, timestamp + "." + body))
+func compute(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,97 @@
+// Package silence 目标临时静音
+//
+// 静音期间探针照常检测，但不发送该目标的故障/恢复告警，也不升级其故障事件。
+// 与告警后的静默期不同，静音由运维人员手动设置，到期自动解除。
+package silence
+
+import (
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/storage"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeLayout 静音时间格式
+const TimeLayout = "2006-01-02 15:04:05"
+
+// MaxDuration 单次静音的最长时间
+const MaxDuration = 30 * 24 * time.Hour
+
+// refreshInterval 重新加载静音记录的间隔（用于感知 CLI 等其他进程的修改）
+const refreshInterval = 5 * time.Second
+
+// cache 未到期的静音记录，按目标索引
+var cache struct {
+	mu       sync.Mutex
+	silences map[string]*storage.TargetSilence
+	loadedAt time.Time
+}
+
+// New 校验参数并创建静音记录
+func New(target string, duration time.Duration, reason, actor string) (*storage.TargetSilence, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, fmt.Errorf("目标不能为空")
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("静音时长必须大于 0")
+	}
+	if duration > MaxDuration {
+		return nil, fmt.Errorf("静音时长不能超过 %v", MaxDuration)
+	}
+
+	now := time.Now()
+	return &storage.TargetSilence{
+		Target:    target,
+		Reason:    strings.TrimSpace(reason),
+		CreatedBy: actor,
+		CreatedAt: now.Format(TimeLayout),
+		ExpiresAt: now.Add(duration).Format(TimeLayout),
+	}, nil
+}
+
+// Invalidate 使缓存失效，下次查询时重新加载
+func Invalidate() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.loadedAt = time.Time{}
+}
+
+// Active 返回目标当前的静音记录，未静音时返回 nil
+func Active(target string) *storage.TargetSilence {
+	now := time.Now()
+	ts := loadSilences(now)[target]
+	if ts == nil || ts.ExpiresAt <= now.Format(TimeLayout) {
+		return nil
+	}
+	return ts
+}
+
+// loadSilences 返回缓存的静音记录，过期时从数据库重新加载
+func loadSilences(now time.Time) map[string]*storage.TargetSilence {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if now.Sub(cache.loadedAt) < refreshInterval {
+		return cache.silences
+	}
+
+	store := storage.GetStorage()
+	if store == nil {
+		return nil
+	}
+	list, err := store.GetActiveTargetSilences(now.Format(TimeLayout))
+	if err != nil {
+		logger.Warnf("[SILENCE] 加载目标静音失败: %v", err)
+		return cache.silences
+	}
+
+	cache.silences = make(map[string]*storage.TargetSilence, len(list))
+	for _, ts := range list {
+		cache.silences[ts.Target] = ts
+	}
+	cache.loadedAt = now
+	return cache.silences
+}
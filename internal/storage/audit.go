@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuditLog 审计日志记录
+type AuditLog struct {
+	ID        int64           `json:"id"`
+	Actor     string          `json:"actor"`
+	Source    string          `json:"source"`
+	SourceIP  string          `json:"source_ip"`
+	Method    string          `json:"method"`
+	Endpoint  string          `json:"endpoint"`
+	Action    string          `json:"action"`
+	Before    string          `json:"-"`
+	After     string          `json:"-"`
+	Diff      string          `json:"-"`
+	BeforeRaw json.RawMessage `json:"before,omitempty"`
+	AfterRaw  json.RawMessage `json:"after,omitempty"`
+	DiffRaw   json.RawMessage `json:"diff,omitempty"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// AuditFilter 审计日志查询条件
+type AuditFilter struct {
+	Actor    string // 操作者（精确匹配）
+	Endpoint string // 路径（前缀匹配）
+	Action   string // 操作描述（包含匹配）
+	Source   string // 来源 api/cli
+	Since    string // 起始时间 (2006-01-02 15:04:05)
+	Until    string // 截止时间 (2006-01-02 15:04:05)
+	Limit    int    // 最大返回条数
+}
+
+// InsertAuditLog 写入审计日志
+func (s *Storage) InsertAuditLog(entry *AuditLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO audit_log (actor, source, source_ip, method, endpoint, action, before_json, after_json, diff_json, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.Actor, entry.Source, entry.SourceIP, entry.Method, entry.Endpoint, entry.Action,
+		entry.Before, entry.After, entry.Diff, entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("写入审计日志失败: %w", err)
+	}
+	return nil
+}
+
+// QueryAuditLogs 按条件查询审计日志（按时间倒序）
+func (s *Storage) QueryAuditLogs(filter AuditFilter) ([]*AuditLog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var conds []string
+	var args []interface{}
+	if filter.Actor != "" {
+		conds = append(conds, "actor = ?")
+		args = append(args, filter.Actor)
+	}
+	if filter.Endpoint != "" {
+		conds = append(conds, "endpoint LIKE ?")
+		args = append(args, filter.Endpoint+"%")
+	}
+	if filter.Action != "" {
+		conds = append(conds, "action LIKE ?")
+		args = append(args, "%"+filter.Action+"%")
+	}
+	if filter.Source != "" {
+		conds = append(conds, "source = ?")
+		args = append(args, filter.Source)
+	}
+	if filter.Since != "" {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, filter.Since)
+	}
+	if filter.Until != "" {
+		conds = append(conds, "created_at <= ?")
+		args = append(args, filter.Until)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	query := `SELECT id, actor, source, source_ip, method, endpoint, action, before_json, after_json, diff_json, created_at FROM audit_log`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询审计日志失败: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*AuditLog
+	for rows.Next() {
+		var entry AuditLog
+		var createdAt time.Time
+		err := rows.Scan(&entry.ID, &entry.Actor, &entry.Source, &entry.SourceIP, &entry.Method, &entry.Endpoint,
+			&entry.Action, &entry.Before, &entry.After, &entry.Diff, &createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("读取审计日志失败: %w", err)
+		}
+		entry.CreatedAt = createdAt.Format("2006-01-02 15:04:05")
+		if entry.Before != "" {
+			entry.BeforeRaw = json.RawMessage(entry.Before)
+		}
+		if entry.After != "" {
+			entry.AfterRaw = json.RawMessage(entry.After)
+		}
+		if entry.Diff != "" {
+			entry.DiffRaw = json.RawMessage(entry.Diff)
+		}
+		logs = append(logs, &entry)
+	}
+	return logs, nil
+}
@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// User 用户（存储用）
+type User struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"-"`
+	Role         string `json:"role"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// APIToken API Token（存储用，仅保存哈希）
+type APIToken struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	TokenHash  string  `json:"-"`
+	Role       string  `json:"role"`
+	CreatedBy  string  `json:"created_by"`
+	CreatedAt  string  `json:"created_at"`
+	LastUsedAt *string `json:"last_used_at"`
+}
+
+// HasCredentials 检查是否已配置任何用户或 Token
+func (s *Storage) HasCredentials() (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int
+	err := s.db.QueryRow(`SELECT (SELECT COUNT(*) FROM users) + (SELECT COUNT(*) FROM api_tokens)`).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("查询认证信息失败: %w", err)
+	}
+	return count > 0, nil
+}
+
+// CountCredentials 统计用户与 Token 总数及其中管理员的数量
+func (s *Storage) CountCredentials() (total, admins int, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	err = s.db.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM users) + (SELECT COUNT(*) FROM api_tokens),
+			(SELECT COUNT(*) FROM users WHERE role = 'admin') + (SELECT COUNT(*) FROM api_tokens WHERE role = 'admin')
+	`).Scan(&total, &admins)
+	if err != nil {
+		return 0, 0, fmt.Errorf("查询认证信息失败: %w", err)
+	}
+	return total, admins, nil
+}
+
+// SaveUser 保存用户（存在则覆盖）
+func (s *Storage) SaveUser(user *User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO users (username, password_hash, role, created_at, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT(username) DO UPDATE SET
+			password_hash = excluded.password_hash,
+			role = excluded.role,
+			updated_at = CURRENT_TIMESTAMP
+	`, user.Username, user.PasswordHash, user.Role)
+	if err != nil {
+		return fmt.Errorf("保存用户失败: %w", err)
+	}
+	return nil
+}
+
+// GetUser 获取用户
+func (s *Storage) GetUser(username string) (*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var user User
+	err := s.db.QueryRow(`
+		SELECT username, password_hash, role, created_at, updated_at FROM users WHERE username = ?
+	`, username).Scan(&user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+	return &user, nil
+}
+
+// GetAllUsers 获取所有用户
+func (s *Storage) GetAllUsers() ([]*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT username, role, created_at, updated_at FROM users ORDER BY username`)
+	if err != nil {
+		return nil, fmt.Errorf("查询用户列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.Username, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("读取用户失败: %w", err)
+		}
+		users = append(users, &user)
+	}
+	return users, nil
+}
+
+// DeleteUser 删除用户
+func (s *Storage) DeleteUser(username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM users WHERE username = ?`, username)
+	if err != nil {
+		return fmt.Errorf("删除用户失败: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return fmt.Errorf("用户不存在: %s", username)
+	}
+	return nil
+}
+
+// SaveAPIToken 保存 API Token
+func (s *Storage) SaveAPIToken(token *APIToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO api_tokens (id, name, token_hash, role, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, token.ID, token.Name, token.TokenHash, token.Role, token.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("保存 Token 失败: %w", err)
+	}
+	return nil
+}
+
+// GetAPITokenByHash 根据哈希查找 API Token
+func (s *Storage) GetAPITokenByHash(hash string) (*APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var token APIToken
+	var createdBy, lastUsedAt sql.NullString
+	err := s.db.QueryRow(`
+		SELECT id, name, token_hash, role, created_by, created_at, last_used_at FROM api_tokens WHERE token_hash = ?
+	`, hash).Scan(&token.ID, &token.Name, &token.TokenHash, &token.Role, &createdBy, &token.CreatedAt, &lastUsedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询 Token 失败: %w", err)
+	}
+
+	token.CreatedBy = createdBy.String
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.String
+	}
+	return &token, nil
+}
+
+// GetAllAPITokens 获取所有 API Token
+func (s *Storage) GetAllAPITokens() ([]*APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`
+		SELECT id, name, role, created_by, created_at, last_used_at FROM api_tokens ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("查询 Token 列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*APIToken
+	for rows.Next() {
+		var token APIToken
+		var createdBy, lastUsedAt sql.NullString
+		if err := rows.Scan(&token.ID, &token.Name, &token.Role, &createdBy, &token.CreatedAt, &lastUsedAt); err != nil {
+			return nil, fmt.Errorf("读取 Token 失败: %w", err)
+		}
+		token.CreatedBy = createdBy.String
+		if lastUsedAt.Valid {
+			token.LastUsedAt = &lastUsedAt.String
+		}
+		tokens = append(tokens, &token)
+	}
+	return tokens, nil
+}
+
+// DeleteAPIToken 吊销 API Token
+func (s *Storage) DeleteAPIToken(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM api_tokens WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("吊销 Token 失败: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return fmt.Errorf("Token 不存在: %s", id)
+	}
+	return nil
+}
+
+// TouchAPIToken 更新 Token 最后使用时间
+func (s *Storage) TouchAPIToken(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`UPDATE api_tokens SET last_used_at = CURRENT_TIMESTAMP WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("更新 Token 使用时间失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// NotifyChannel 通知通道（存储用）
+type NotifyChannel struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Enabled   bool              `json:"enabled"`
+	URL       string            `json:"url"`
+	Secret    string            `json:"secret"` // 共享密钥：钉钉/飞书加签，并用于 X-Signature 签名
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	Template  string            `json:"template"`        // 自定义消息体模板（text/template）
+	Email     *EmailConfig      `json:"email,omitempty"` // 邮件通道的 SMTP 配置
+	Timeout   int               `json:"timeout"`
+	Retry     int               `json:"retry"`
+	CreatedAt string            `json:"created_at"`
+	UpdatedAt string            `json:"updated_at"`
+}
+
+// EmailConfig SMTP 邮件通道配置
+type EmailConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	TLS      string   `json:"tls"` // starttls（默认）/ tls（隐式 TLS）/ none
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// RouteRule 告警路由规则（存储用）
+// 各匹配条件为空表示不限制，多个条件需同时满足；同一条件内任一值匹配即可
+type RouteRule struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Enabled    bool     `json:"enabled"`
+	Tags       []string `json:"tags"`        // 目标标签
+	ProbeTypes []string `json:"probe_types"` // 探针类型 (ping/tcp/http)
+	AlertTypes []string `json:"alert_types"` // 告警类型 (down/recovery)
+	Severities []string `json:"severities"`  // 告警级别
+	Channels   []string `json:"channels"`    // 通知通道 ID
+	Escalation string   `json:"escalation"`  // 升级策略 ID（可选，仅对故障告警生效）
+	CreatedAt  string   `json:"created_at"`
+	UpdatedAt  string   `json:"updated_at"`
+}
+
+const channelColumns = `id, name, type, enabled, url, secret, method, headers, template, email, timeout, retry, created_at, updated_at`
+
+const routeColumns = `id, name, enabled, tags, probe_types, alert_types, severities, channels, escalation, created_at, updated_at`
+
+// SaveChannel 保存通知通道（存在则覆盖）
+func (s *Storage) SaveChannel(ch *NotifyChannel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	headers := ""
+	if ch.Headers != nil {
+		data, _ := json.Marshal(ch.Headers)
+		headers = string(data)
+	}
+	email := ""
+	if ch.Email != nil {
+		data, _ := json.Marshal(ch.Email)
+		email = string(data)
+	}
+
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO notify_channels (`+channelColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, ch.ID, ch.Name, ch.Type, ch.Enabled, ch.URL, ch.Secret, ch.Method, headers, ch.Template, email, ch.Timeout, ch.Retry, ch.CreatedAt, ch.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("保存通知通道失败: %w", err)
+	}
+	return nil
+}
+
+// GetChannel 获取单个通知通道
+func (s *Storage) GetChannel(id string) (*NotifyChannel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ch, err := scanChannel(s.db.QueryRow(`SELECT `+channelColumns+` FROM notify_channels WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询通知通道失败: %w", err)
+	}
+	return ch, nil
+}
+
+// GetAllChannels 获取所有通知通道
+func (s *Storage) GetAllChannels() ([]*NotifyChannel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT ` + channelColumns + ` FROM notify_channels ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询通知通道列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var channels []*NotifyChannel
+	for rows.Next() {
+		ch, err := scanChannel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取通知通道失败: %w", err)
+		}
+		channels = append(channels, ch)
+	}
+	return channels, nil
+}
+
+// DeleteChannel 删除通知通道
+func (s *Storage) DeleteChannel(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM notify_channels WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除通知通道失败: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return fmt.Errorf("通知通道不存在: %s", id)
+	}
+	return nil
+}
+
+// SaveRouteRule 保存路由规则（存在则覆盖）
+func (s *Storage) SaveRouteRule(rule *RouteRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO notify_routes (`+routeColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.ID, rule.Name, rule.Enabled, encodeList(rule.Tags), encodeList(rule.ProbeTypes), encodeList(rule.AlertTypes),
+		encodeList(rule.Severities), encodeList(rule.Channels), rule.Escalation, rule.CreatedAt, rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("保存路由规则失败: %w", err)
+	}
+	return nil
+}
+
+// GetRouteRule 获取单条路由规则
+func (s *Storage) GetRouteRule(id string) (*RouteRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rule, err := scanRouteRule(s.db.QueryRow(`SELECT `+routeColumns+` FROM notify_routes WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询路由规则失败: %w", err)
+	}
+	return rule, nil
+}
+
+// GetAllRouteRules 获取所有路由规则（按创建时间排序）
+func (s *Storage) GetAllRouteRules() ([]*RouteRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT ` + routeColumns + ` FROM notify_routes ORDER BY created_at, name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询路由规则列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*RouteRule
+	for rows.Next() {
+		rule, err := scanRouteRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取路由规则失败: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// DeleteRouteRule 删除路由规则
+func (s *Storage) DeleteRouteRule(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM notify_routes WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除路由规则失败: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return fmt.Errorf("路由规则不存在: %s", id)
+	}
+	return nil
+}
+
+// scanChannel 读取一条通知通道
+func scanChannel(row outboxScanner) (*NotifyChannel, error) {
+	var ch NotifyChannel
+	var enabled int
+	var headers, email string
+
+	err := row.Scan(&ch.ID, &ch.Name, &ch.Type, &enabled, &ch.URL, &ch.Secret, &ch.Method, &headers, &ch.Template, &email, &ch.Timeout, &ch.Retry,
+		&ch.CreatedAt, &ch.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	ch.Enabled = enabled == 1
+	if headers != "" {
+		json.Unmarshal([]byte(headers), &ch.Headers)
+	}
+	if email != "" {
+		json.Unmarshal([]byte(email), &ch.Email)
+	}
+	return &ch, nil
+}
+
+// scanRouteRule 读取一条路由规则
+func scanRouteRule(row outboxScanner) (*RouteRule, error) {
+	var rule RouteRule
+	var enabled int
+	var tags, probeTypes, alertTypes, severities, channels string
+
+	err := row.Scan(&rule.ID, &rule.Name, &enabled, &tags, &probeTypes, &alertTypes, &severities, &channels, &rule.Escalation,
+		&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Enabled = enabled == 1
+	rule.Tags = decodeList(tags)
+	rule.ProbeTypes = decodeList(probeTypes)
+	rule.AlertTypes = decodeList(alertTypes)
+	rule.Severities = decodeList(severities)
+	rule.Channels = decodeList(channels)
+	return &rule, nil
+}
+
+// encodeList 将字符串列表编码为 JSON 文本
+func encodeList(list []string) string {
+	if len(list) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(list)
+	return string(data)
+}
+
+// decodeList 解析 JSON 文本为字符串列表
+func decodeList(value string) []string {
+	list := []string{}
+	if value != "" {
+		json.Unmarshal([]byte(value), &list)
+	}
+	return list
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CheckResultRetention 每个目标保留的检测结果条数
+const CheckResultRetention = 1000
+
+// CheckResult 一次检测的结构化结果（耗时单位均为毫秒）
+type CheckResult struct {
+	ID            int64              `json:"id"`
+	Target        string             `json:"target"`
+	ProbeType     string             `json:"probe_type"`
+	Success       bool               `json:"success"`
+	LatencyMs     float64            `json:"latency_ms"`
+	ResolvedIP    string             `json:"resolved_ip,omitempty"`
+	StatusCode    int                `json:"status_code,omitempty"`
+	Timings       CheckTimings       `json:"timings"`
+	RTT           *RTTStats          `json:"rtt,omitempty"`         // 仅 Ping
+	PacketLoss    float64            `json:"packet_loss,omitempty"` // 丢包率（%，仅 Ping）
+	Error         string             `json:"error,omitempty"`
+	ErrorCategory string             `json:"error_category,omitempty"` // timeout/refused/dns/tls/assertion/other
+	Metrics       map[string]float64 `json:"metrics,omitempty"`
+	CheckedAt     string             `json:"checked_at"`
+}
+
+// CheckTimings 检测各阶段耗时（毫秒）
+type CheckTimings struct {
+	DNSMs     float64 `json:"dns_ms"`
+	ConnectMs float64 `json:"connect_ms"`
+	TLSMs     float64 `json:"tls_ms"`
+	TTFBMs    float64 `json:"ttfb_ms"`
+	TotalMs   float64 `json:"total_ms"`
+}
+
+// RTTStats Ping 往返时间统计（毫秒）
+type RTTStats struct {
+	MinMs    float64 `json:"min_ms"`
+	AvgMs    float64 `json:"avg_ms"`
+	MaxMs    float64 `json:"max_ms"`
+	StdDevMs float64 `json:"stddev_ms"`
+}
+
+const checkResultColumns = `id, target, probe_type, success, latency_ms, resolved_ip, status_code, timings, rtt, packet_loss, error, error_category, metrics, checked_at`
+
+// InsertCheckResult 写入检测结果，并清理该目标超出保留条数的旧记录
+func (s *Storage) InsertCheckResult(r *CheckResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	timings, _ := json.Marshal(r.Timings)
+	rtt := ""
+	if r.RTT != nil {
+		data, _ := json.Marshal(r.RTT)
+		rtt = string(data)
+	}
+	metrics := ""
+	if len(r.Metrics) > 0 {
+		data, _ := json.Marshal(r.Metrics)
+		metrics = string(data)
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO check_results (target, probe_type, success, latency_ms, resolved_ip, status_code, timings, rtt, packet_loss, error, error_category, metrics, checked_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, r.Target, r.ProbeType, r.Success, r.LatencyMs, r.ResolvedIP, r.StatusCode, string(timings), rtt, r.PacketLoss,
+		r.Error, r.ErrorCategory, metrics, r.CheckedAt)
+	if err != nil {
+		return fmt.Errorf("写入检测结果失败: %w", err)
+	}
+	r.ID, _ = result.LastInsertId()
+
+	_, err = s.db.Exec(`DELETE FROM check_results WHERE target = ? AND id < (
+		SELECT id FROM check_results WHERE target = ? ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+		r.Target, r.Target, CheckResultRetention-1)
+	if err != nil {
+		return fmt.Errorf("清理检测结果失败: %w", err)
+	}
+	return nil
+}
+
+// QueryCheckResults 查询目标最近的检测结果（按时间倒序），since 非空时只返回该时间之后的记录
+func (s *Storage) QueryCheckResults(target, since string, limit int) ([]*CheckResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if limit <= 0 || limit > CheckResultRetention {
+		limit = 100
+	}
+
+	rows, err := s.db.Query(`SELECT `+checkResultColumns+` FROM check_results
+		WHERE target = ? AND checked_at >= ? ORDER BY id DESC LIMIT ?`, target, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询检测结果失败: %w", err)
+	}
+	defer rows.Close()
+
+	results := []*CheckResult{}
+	for rows.Next() {
+		var r CheckResult
+		var timings, rtt, metrics string
+		err := rows.Scan(&r.ID, &r.Target, &r.ProbeType, &r.Success, &r.LatencyMs, &r.ResolvedIP, &r.StatusCode,
+			&timings, &rtt, &r.PacketLoss, &r.Error, &r.ErrorCategory, &metrics, &r.CheckedAt)
+		if err != nil {
+			return nil, fmt.Errorf("读取检测结果失败: %w", err)
+		}
+		json.Unmarshal([]byte(timings), &r.Timings)
+		if rtt != "" {
+			r.RTT = &RTTStats{}
+			json.Unmarshal([]byte(rtt), r.RTT)
+		}
+		if metrics != "" {
+			json.Unmarshal([]byte(metrics), &r.Metrics)
+		}
+		results = append(results, &r)
+	}
+	return results, nil
+}
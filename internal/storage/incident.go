@@ -0,0 +1,303 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// 事件状态
+const (
+	IncidentOpen         = "open"         // 故障中，未确认
+	IncidentAcknowledged = "acknowledged" // 已确认，停止升级
+	IncidentResolved     = "resolved"     // 已恢复
+)
+
+// 事件时间线类型
+const (
+	IncidentEventOpened       = "opened"       // 事件创建（首次故障告警）
+	IncidentEventEscalated    = "escalated"    // 执行了一个升级步骤
+	IncidentEventAcknowledged = "acknowledged" // 被确认
+	IncidentEventResolved     = "resolved"     // 目标恢复
+)
+
+// EscalationStep 升级步骤：事件创建后 After 分钟仍未确认时通知 Channels
+type EscalationStep struct {
+	After    int      `json:"after"`    // 距事件创建的分钟数
+	Channels []string `json:"channels"` // 通知通道 ID
+}
+
+// EscalationPolicy 告警升级策略（存储用）
+type EscalationPolicy struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	Enabled   bool             `json:"enabled"`
+	Steps     []EscalationStep `json:"steps"`
+	CreatedAt string           `json:"created_at"`
+	UpdatedAt string           `json:"updated_at"`
+}
+
+// Incident 故障事件（同一目标与探针类型同时最多一个未恢复的事件）
+type Incident struct {
+	ID             int64            `json:"id"`
+	Target         string           `json:"target"`
+	ProbeType      string           `json:"probe_type"`
+	Severity       string           `json:"severity"`
+	Tags           []string         `json:"tags"`
+	Error          string           `json:"error"`
+	Status         string           `json:"status"`
+	PolicyID       string           `json:"policy_id"` // 升级策略 ID，为空表示不升级
+	Step           int              `json:"step"`      // 已执行的升级步骤数
+	StartedAt      string           `json:"started_at"`
+	AcknowledgedAt string           `json:"acknowledged_at"`
+	AcknowledgedBy string           `json:"acknowledged_by"`
+	ResolvedAt     string           `json:"resolved_at"`
+	Events         []*IncidentEvent `json:"events,omitempty"`
+}
+
+// IncidentEvent 事件时间线记录
+type IncidentEvent struct {
+	ID         int64    `json:"id"`
+	IncidentID int64    `json:"incident_id"`
+	Type       string   `json:"type"`
+	Step       int      `json:"step"`     // 升级步骤序号（从 1 开始）
+	Channels   []string `json:"channels"` // 本次通知的通道名称
+	Actor      string   `json:"actor"`
+	Message    string   `json:"message"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+const incidentColumns = `id, target, probe_type, severity, tags, error, status, policy_id, step, started_at, acknowledged_at, acknowledged_by, resolved_at`
+
+// SaveEscalationPolicy 保存升级策略（存在则覆盖）
+func (s *Storage) SaveEscalationPolicy(policy *EscalationPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	steps, _ := json.Marshal(policy.Steps)
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO escalation_policies (id, name, enabled, steps, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, policy.ID, policy.Name, policy.Enabled, string(steps), policy.CreatedAt, policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("保存升级策略失败: %w", err)
+	}
+	return nil
+}
+
+// GetEscalationPolicy 获取单个升级策略
+func (s *Storage) GetEscalationPolicy(id string) (*EscalationPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policy, err := scanEscalationPolicy(s.db.QueryRow(`SELECT id, name, enabled, steps, created_at, updated_at
+		FROM escalation_policies WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询升级策略失败: %w", err)
+	}
+	return policy, nil
+}
+
+// GetAllEscalationPolicies 获取所有升级策略
+func (s *Storage) GetAllEscalationPolicies() ([]*EscalationPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT id, name, enabled, steps, created_at, updated_at FROM escalation_policies ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询升级策略列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*EscalationPolicy
+	for rows.Next() {
+		policy, err := scanEscalationPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取升级策略失败: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// DeleteEscalationPolicy 删除升级策略
+func (s *Storage) DeleteEscalationPolicy(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM escalation_policies WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除升级策略失败: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return fmt.Errorf("升级策略不存在: %s", id)
+	}
+	return nil
+}
+
+// CreateIncident 创建故障事件，返回事件 ID
+func (s *Storage) CreateIncident(inc *Incident) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`
+		INSERT INTO incidents (target, probe_type, severity, tags, error, status, policy_id, step, started_at, acknowledged_at, acknowledged_by, resolved_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, inc.Target, inc.ProbeType, inc.Severity, encodeList(inc.Tags), inc.Error, inc.Status, inc.PolicyID, inc.Step,
+		inc.StartedAt, inc.AcknowledgedAt, inc.AcknowledgedBy, inc.ResolvedAt)
+	if err != nil {
+		return 0, fmt.Errorf("创建故障事件失败: %w", err)
+	}
+
+	inc.ID, _ = result.LastInsertId()
+	return inc.ID, nil
+}
+
+// UpdateIncident 更新事件状态与升级进度
+func (s *Storage) UpdateIncident(inc *Incident) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		UPDATE incidents SET status = ?, step = ?, acknowledged_at = ?, acknowledged_by = ?, resolved_at = ?
+		WHERE id = ?
+	`, inc.Status, inc.Step, inc.AcknowledgedAt, inc.AcknowledgedBy, inc.ResolvedAt, inc.ID)
+	if err != nil {
+		return fmt.Errorf("更新故障事件失败: %w", err)
+	}
+	return nil
+}
+
+// GetIncident 获取单个事件（不含时间线）
+func (s *Storage) GetIncident(id int64) (*Incident, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	inc, err := scanIncident(s.db.QueryRow(`SELECT `+incidentColumns+` FROM incidents WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询故障事件失败: %w", err)
+	}
+	return inc, nil
+}
+
+// QueryIncidents 按状态查询事件（按 ID 倒序），status 为空时返回全部，可用逗号分隔多个状态
+func (s *Storage) QueryIncidents(status string, limit int) ([]*Incident, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	query := `SELECT ` + incidentColumns + ` FROM incidents`
+	var args []interface{}
+	if status != "" {
+		statuses := strings.Split(status, ",")
+		query += " WHERE status IN (?" + strings.Repeat(", ?", len(statuses)-1) + ")"
+		for _, st := range statuses {
+			args = append(args, strings.TrimSpace(st))
+		}
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询故障事件失败: %w", err)
+	}
+	defer rows.Close()
+
+	incidents := []*Incident{}
+	for rows.Next() {
+		inc, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取故障事件失败: %w", err)
+		}
+		incidents = append(incidents, inc)
+	}
+	return incidents, nil
+}
+
+// AddIncidentEvent 追加事件时间线记录
+func (s *Storage) AddIncidentEvent(ev *IncidentEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`
+		INSERT INTO incident_events (incident_id, type, step, channels, actor, message, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, ev.IncidentID, ev.Type, ev.Step, encodeList(ev.Channels), ev.Actor, ev.Message, ev.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("记录事件时间线失败: %w", err)
+	}
+
+	ev.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// GetIncidentEvents 获取事件时间线（按时间顺序）
+func (s *Storage) GetIncidentEvents(incidentID int64) ([]*IncidentEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT id, incident_id, type, step, channels, actor, message, created_at
+		FROM incident_events WHERE incident_id = ? ORDER BY id`, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("查询事件时间线失败: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*IncidentEvent{}
+	for rows.Next() {
+		var ev IncidentEvent
+		var channels string
+		if err := rows.Scan(&ev.ID, &ev.IncidentID, &ev.Type, &ev.Step, &channels, &ev.Actor, &ev.Message, &ev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("读取事件时间线失败: %w", err)
+		}
+		ev.Channels = decodeList(channels)
+		events = append(events, &ev)
+	}
+	return events, nil
+}
+
+// scanEscalationPolicy 读取一条升级策略
+func scanEscalationPolicy(row outboxScanner) (*EscalationPolicy, error) {
+	var policy EscalationPolicy
+	var enabled int
+	var steps string
+
+	if err := row.Scan(&policy.ID, &policy.Name, &enabled, &steps, &policy.CreatedAt, &policy.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	policy.Enabled = enabled == 1
+	policy.Steps = []EscalationStep{}
+	if steps != "" {
+		json.Unmarshal([]byte(steps), &policy.Steps)
+	}
+	return &policy, nil
+}
+
+// scanIncident 读取一条故障事件
+func scanIncident(row outboxScanner) (*Incident, error) {
+	var inc Incident
+	var tags string
+
+	err := row.Scan(&inc.ID, &inc.Target, &inc.ProbeType, &inc.Severity, &tags, &inc.Error, &inc.Status, &inc.PolicyID, &inc.Step,
+		&inc.StartedAt, &inc.AcknowledgedAt, &inc.AcknowledgedBy, &inc.ResolvedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	inc.Tags = decodeList(tags)
+	return &inc, nil
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MaintenanceWindow 维护窗口（存储用）
+// 一次性窗口使用 StartAt/EndAt；周期窗口使用 Cron 表达式指定开始时间，持续 Duration 分钟
+type MaintenanceWindow struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Targets   []string `json:"targets"`  // 生效的目标
+	Tags      []string `json:"tags"`     // 生效的目标标签（命中任一即可）
+	StartAt   string   `json:"start_at"` // 一次性窗口开始时间
+	EndAt     string   `json:"end_at"`   // 一次性窗口结束时间
+	Cron      string   `json:"cron"`     // 周期窗口的开始时间（标准 5 段 Cron 表达式）
+	Duration  int      `json:"duration"` // 周期窗口持续分钟数
+	Comment   string   `json:"comment"`
+	CreatedBy string   `json:"created_by"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+const maintenanceColumns = `id, name, targets, tags, start_at, end_at, cron, duration, comment, created_by, created_at, updated_at`
+
+// SaveMaintenanceWindow 保存维护窗口（存在则覆盖）
+func (s *Storage) SaveMaintenanceWindow(mw *MaintenanceWindow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO maintenance_windows (`+maintenanceColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, mw.ID, mw.Name, encodeList(mw.Targets), encodeList(mw.Tags), mw.StartAt, mw.EndAt, mw.Cron, mw.Duration,
+		mw.Comment, mw.CreatedBy, mw.CreatedAt, mw.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("保存维护窗口失败: %w", err)
+	}
+	return nil
+}
+
+// GetMaintenanceWindow 获取单个维护窗口
+func (s *Storage) GetMaintenanceWindow(id string) (*MaintenanceWindow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	mw, err := scanMaintenanceWindow(s.db.QueryRow(`SELECT `+maintenanceColumns+` FROM maintenance_windows WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询维护窗口失败: %w", err)
+	}
+	return mw, nil
+}
+
+// GetAllMaintenanceWindows 获取所有维护窗口（按创建时间排序）
+func (s *Storage) GetAllMaintenanceWindows() ([]*MaintenanceWindow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT ` + maintenanceColumns + ` FROM maintenance_windows ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("查询维护窗口列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	windows := []*MaintenanceWindow{}
+	for rows.Next() {
+		mw, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取维护窗口失败: %w", err)
+		}
+		windows = append(windows, mw)
+	}
+	return windows, nil
+}
+
+// DeleteMaintenanceWindow 删除维护窗口
+func (s *Storage) DeleteMaintenanceWindow(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM maintenance_windows WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除维护窗口失败: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return fmt.Errorf("维护窗口不存在: %s", id)
+	}
+	return nil
+}
+
+// scanMaintenanceWindow 读取一条维护窗口
+func scanMaintenanceWindow(row outboxScanner) (*MaintenanceWindow, error) {
+	var mw MaintenanceWindow
+	var targets, tags string
+
+	err := row.Scan(&mw.ID, &mw.Name, &targets, &tags, &mw.StartAt, &mw.EndAt, &mw.Cron, &mw.Duration,
+		&mw.Comment, &mw.CreatedBy, &mw.CreatedAt, &mw.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	mw.Targets = decodeList(targets)
+	mw.Tags = decodeList(tags)
+	return &mw, nil
+}
@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 投递状态
+const (
+	OutboxPending   = "pending"   // 等待（重新）投递
+	OutboxDelivered = "delivered" // 投递成功
+	OutboxFailed    = "failed"    // 重试耗尽
+)
+
+// OutboxEntry Webhook 投递记录（请求内容在入队时固定，重放时原样发送）
+type OutboxEntry struct {
+	ID            int64             `json:"id"`
+	Channel       string            `json:"channel"` // 通知通道名称
+	Format        string            `json:"format"`  // 消息格式（通道类型）
+	Secret        string            `json:"-"`       // 加签密钥，投递时计算签名
+	AlertType     string            `json:"alert_type"`
+	Target        string            `json:"target"`
+	URL           string            `json:"url"`
+	Method        string            `json:"method"`
+	Headers       map[string]string `json:"headers"`
+	Timeout       int               `json:"timeout"` // 请求超时（秒），0 表示使用默认值
+	Body          string            `json:"body"`
+	Status        string            `json:"status"`
+	Attempts      int               `json:"attempts"`
+	MaxAttempts   int               `json:"max_attempts"`
+	LastError     string            `json:"last_error"`
+	NextAttemptAt string            `json:"next_attempt_at"`
+	CreatedAt     string            `json:"created_at"`
+	UpdatedAt     string            `json:"updated_at"`
+}
+
+const outboxColumns = `id, channel, format, secret, alert_type, target, url, method, headers, timeout, body, status, attempts, max_attempts, last_error, next_attempt_at, created_at, updated_at`
+
+// InsertOutbox 写入一条待投递记录，返回记录 ID
+func (s *Storage) InsertOutbox(entry *OutboxEntry) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	headers := ""
+	if entry.Headers != nil {
+		data, _ := json.Marshal(entry.Headers)
+		headers = string(data)
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO webhook_outbox (channel, format, secret, alert_type, target, url, method, headers, timeout, body, status, attempts, max_attempts, last_error, next_attempt_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.Channel, entry.Format, entry.Secret, entry.AlertType, entry.Target, entry.URL, entry.Method, headers, entry.Timeout, entry.Body, entry.Status, entry.Attempts,
+		entry.MaxAttempts, entry.LastError, entry.NextAttemptAt, entry.CreatedAt, entry.UpdatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("写入投递记录失败: %w", err)
+	}
+
+	entry.ID, _ = result.LastInsertId()
+	return entry.ID, nil
+}
+
+// GetOutbox 获取单条投递记录
+func (s *Storage) GetOutbox(id int64) (*OutboxEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, err := scanOutbox(s.db.QueryRow(`SELECT `+outboxColumns+` FROM webhook_outbox WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询投递记录失败: %w", err)
+	}
+	return entry, nil
+}
+
+// GetDueOutbox 获取已到重试时间的待投递记录
+func (s *Storage) GetDueOutbox(now string, limit int) ([]*OutboxEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT `+outboxColumns+` FROM webhook_outbox
+		WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?`, OutboxPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询待投递记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOutboxRows(rows)
+}
+
+// QueryOutbox 按状态查询投递记录（按 ID 倒序），status 为空时返回全部
+func (s *Storage) QueryOutbox(status string, limit int) ([]*OutboxEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	var conds []string
+	var args []interface{}
+	if status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, status)
+	}
+
+	query := `SELECT ` + outboxColumns + ` FROM webhook_outbox`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询投递记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOutboxRows(rows)
+}
+
+// CountOutbox 统计各状态的投递记录数量
+func (s *Storage) CountOutbox() (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT status, COUNT(*) FROM webhook_outbox GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("统计投递记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{OutboxPending: 0, OutboxDelivered: 0, OutboxFailed: 0}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("统计投递记录失败: %w", err)
+		}
+		counts[status] = count
+	}
+	return counts, nil
+}
+
+// UpdateOutboxAttempt 记录一次投递结果
+func (s *Storage) UpdateOutboxAttempt(id int64, status string, attempts int, lastError, nextAttemptAt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		UPDATE webhook_outbox SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id = ?
+	`, status, attempts, lastError, nextAttemptAt, time.Now().Format("2006-01-02 15:04:05"), id)
+	if err != nil {
+		return fmt.Errorf("更新投递记录失败: %w", err)
+	}
+	return nil
+}
+
+// ReplayOutbox 将投递记录重置为待投递（重新计算重试次数）
+func (s *Storage) ReplayOutbox(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := s.db.Exec(`
+		UPDATE webhook_outbox SET status = ?, attempts = 0, last_error = '', next_attempt_at = ?, updated_at = ?
+		WHERE id = ?
+	`, OutboxPending, now, now, id)
+	if err != nil {
+		return fmt.Errorf("重放投递记录失败: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return fmt.Errorf("投递记录不存在: %d", id)
+	}
+	return nil
+}
+
+// outboxScanner 兼容 *sql.Row 与 *sql.Rows
+type outboxScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanOutbox 读取一条投递记录
+func scanOutbox(row outboxScanner) (*OutboxEntry, error) {
+	var entry OutboxEntry
+	var headers string
+	var nextAttemptAt, createdAt, updatedAt time.Time
+
+	err := row.Scan(&entry.ID, &entry.Channel, &entry.Format, &entry.Secret, &entry.AlertType, &entry.Target, &entry.URL, &entry.Method, &headers, &entry.Timeout, &entry.Body,
+		&entry.Status, &entry.Attempts, &entry.MaxAttempts, &entry.LastError, &nextAttemptAt, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if headers != "" {
+		json.Unmarshal([]byte(headers), &entry.Headers)
+	}
+	entry.NextAttemptAt = nextAttemptAt.Format("2006-01-02 15:04:05")
+	entry.CreatedAt = createdAt.Format("2006-01-02 15:04:05")
+	entry.UpdatedAt = updatedAt.Format("2006-01-02 15:04:05")
+	return &entry, nil
+}
+
+// scanOutboxRows 读取多条投递记录
+func scanOutboxRows(rows *sql.Rows) ([]*OutboxEntry, error) {
+	var entries []*OutboxEntry
+	for rows.Next() {
+		entry, err := scanOutbox(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取投递记录失败: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ConfigRevision 配置版本
+type ConfigRevision struct {
+	ID        int64       `json:"id"`
+	Author    string      `json:"author"`
+	Comment   string      `json:"comment"`
+	CreatedAt string      `json:"created_at"`
+	Config    *FullConfig `json:"config,omitempty"`
+}
+
+// insertConfigRevision 在事务中写入一个配置版本
+func insertConfigRevision(tx *sql.Tx, value, author, comment string) (int64, error) {
+	result, err := tx.Exec(`
+		INSERT INTO config_revisions (value, author, comment, created_at) VALUES (?, ?, ?, ?)
+	`, value, author, comment, time.Now().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("保存配置版本失败: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// ensureInitialRevision 已有配置但没有任何版本时，将当前配置记录为初始版本
+func (s *Storage) ensureInitialRevision() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM config_revisions`).Scan(&count); err != nil {
+		return fmt.Errorf("查询配置版本失败: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM config WHERE key = 'main_config'`).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取配置失败: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := insertConfigRevision(tx, value, "system", "初始版本"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetConfigRevisions 获取配置版本列表（不含配置内容，按版本号倒序）
+func (s *Storage) GetConfigRevisions(limit int) ([]*ConfigRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if limit <= 0 {
+		limit = 50
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, author, comment, created_at FROM config_revisions ORDER BY id DESC LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询配置版本失败: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*ConfigRevision
+	for rows.Next() {
+		var rev ConfigRevision
+		var createdAt time.Time
+		if err := rows.Scan(&rev.ID, &rev.Author, &rev.Comment, &createdAt); err != nil {
+			return nil, fmt.Errorf("读取配置版本失败: %w", err)
+		}
+		rev.CreatedAt = createdAt.Format("2006-01-02 15:04:05")
+		revisions = append(revisions, &rev)
+	}
+	return revisions, nil
+}
+
+// GetConfigRevision 获取指定版本（含配置内容）
+func (s *Storage) GetConfigRevision(id int64) (*ConfigRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rev ConfigRevision
+	var value string
+	var createdAt time.Time
+	err := s.db.QueryRow(`
+		SELECT id, value, author, comment, created_at FROM config_revisions WHERE id = ?
+	`, id).Scan(&rev.ID, &value, &rev.Author, &rev.Comment, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询配置版本失败: %w", err)
+	}
+
+	rev.CreatedAt = createdAt.Format("2006-01-02 15:04:05")
+	rev.Config = &FullConfig{}
+	if err := json.Unmarshal([]byte(value), rev.Config); err != nil {
+		return nil, fmt.Errorf("解析配置版本失败: %w", err)
+	}
+	return &rev, nil
+}
+
+// GetLatestConfigRevisionID 获取最新配置版本号，没有版本时返回 0
+func (s *Storage) GetLatestConfigRevisionID() (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var id sql.NullInt64
+	if err := s.db.QueryRow(`SELECT MAX(id) FROM config_revisions`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("查询最新配置版本失败: %w", err)
+	}
+	return id.Int64, nil
+}
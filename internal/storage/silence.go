@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// TargetSilence 目标静音记录：到期前不发送该目标的告警通知
+type TargetSilence struct {
+	Target    string `json:"target"`
+	Reason    string `json:"reason"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// SaveTargetSilence 保存目标静音（已静音时覆盖）
+func (s *Storage) SaveTargetSilence(ts *TargetSilence) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO target_silences (target, reason, created_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, ts.Target, ts.Reason, ts.CreatedBy, ts.CreatedAt, ts.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("保存目标静音失败: %w", err)
+	}
+	return nil
+}
+
+// GetTargetSilence 获取目标的静音记录（可能已过期）
+func (s *Storage) GetTargetSilence(target string) (*TargetSilence, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ts TargetSilence
+	err := s.db.QueryRow(`SELECT target, reason, created_by, created_at, expires_at FROM target_silences WHERE target = ?`, target).
+		Scan(&ts.Target, &ts.Reason, &ts.CreatedBy, &ts.CreatedAt, &ts.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询目标静音失败: %w", err)
+	}
+	return &ts, nil
+}
+
+// GetActiveTargetSilences 获取在指定时间仍未到期的静音记录（按到期时间排序）
+func (s *Storage) GetActiveTargetSilences(now string) ([]*TargetSilence, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT target, reason, created_by, created_at, expires_at
+		FROM target_silences WHERE expires_at > ? ORDER BY expires_at`, now)
+	if err != nil {
+		return nil, fmt.Errorf("查询目标静音失败: %w", err)
+	}
+	defer rows.Close()
+
+	silences := []*TargetSilence{}
+	for rows.Next() {
+		var ts TargetSilence
+		if err := rows.Scan(&ts.Target, &ts.Reason, &ts.CreatedBy, &ts.CreatedAt, &ts.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("读取目标静音失败: %w", err)
+		}
+		silences = append(silences, &ts)
+	}
+	return silences, nil
+}
+
+// DeleteTargetSilence 解除目标静音
+func (s *Storage) DeleteTargetSilence(target string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM target_silences WHERE target = ?`, target)
+	if err != nil {
+		return fmt.Errorf("解除目标静音失败: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return fmt.Errorf("目标未静音: %s", target)
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ type ProbeConfig struct {
 	Enabled          bool     `json:"enabled"`
 	Frequency        int      `json:"frequency"`
 	FailCount        int      `json:"failcount"`
+	RecoveryCount    int      `json:"recovery_count"` // 故障后连续成功多少次才判定恢复
 	Timeout          int      `json:"timeout"`
 	Retry            int      `json:"retry"`
 	RemoteUpdateFreq int      `json:"remote_update_freq"`
@@ -28,20 +29,63 @@ type ProbeConfig struct {
 
 // WebhookConfig Webhook 配置
 type WebhookConfig struct {
-	URL           string            `json:"url"`
-	Method        string            `json:"method"`
-	Headers       map[string]string `json:"headers"`
-	Timeout       int               `json:"timeout"`
-	Retry         int               `json:"retry"`
-	SilencePeriod int               `json:"silence_period"` // 静默期（秒）
+	Type             string            `json:"type"` // 消息格式: webhook/dingtalk/feishu/slack/telegram/wecom
+	URL              string            `json:"url"`
+	Secret           string            `json:"secret"` // 共享密钥：钉钉/飞书加签，并用于 X-Signature 签名
+	Method           string            `json:"method"`
+	Headers          map[string]string `json:"headers"`
+	Template         string            `json:"template"` // 自定义消息体模板（text/template）
+	Timeout          int               `json:"timeout"`
+	Retry            int               `json:"retry"`
+	SilencePeriod    int               `json:"silence_period"`    // 静默期（秒）
+	ReminderInterval int               `json:"reminder_interval"` // 持续故障时的提醒间隔（秒），0 表示不发送提醒
+}
+
+// TargetMeta 检测目标的附加信息，用于告警路由
+type TargetMeta struct {
+	Tags          []string     `json:"tags,omitempty"`
+	Severity      string       `json:"severity,omitempty"`       // 告警级别，默认 critical
+	RecoveryCount int          `json:"recovery_count,omitempty"` // 恢复阈值，0 表示沿用探针配置
+	Thresholds    *Thresholds  `json:"thresholds,omitempty"`     // 性能降级阈值
+	DependsOn     []string     `json:"depends_on,omitempty"`     // 上游依赖目标，上游故障时抑制本目标告警
+	Ping          *PingOptions `json:"ping,omitempty"`           // Ping 检测参数
+}
+
+// PingOptions Ping 检测参数（0 表示使用默认值）
+type PingOptions struct {
+	Count         int `json:"count,omitempty"`          // 发送包数，默认 4
+	Interval      int `json:"interval,omitempty"`       // 发包间隔（毫秒），默认 300
+	Size          int `json:"size,omitempty"`           // 负载大小（字节），默认 24，不能小于 24
+	TTL           int `json:"ttl,omitempty"`            // 默认 64
+	LossThreshold int `json:"loss_threshold,omitempty"` // 丢包率达到该值（%）判定失败，默认 100 即全部丢包才失败
+}
+
+// Thresholds 性能降级阈值，超过 warning/critical 阈值时目标进入降级状态（0 表示不检查）
+type Thresholds struct {
+	LatencyWarning  int `json:"latency_warning,omitempty"`  // 延迟（毫秒）
+	LatencyCritical int `json:"latency_critical,omitempty"` // 延迟（毫秒）
+	LossWarning     int `json:"loss_warning,omitempty"`     // 丢包率（%，仅 Ping）
+	LossCritical    int `json:"loss_critical,omitempty"`    // 丢包率（%，仅 Ping）
+	JitterWarning   int `json:"jitter_warning,omitempty"`   // 抖动（毫秒）
+	JitterCritical  int `json:"jitter_critical,omitempty"`  // 抖动（毫秒）
+}
+
+// FlapConfig 抖动检测配置
+type FlapConfig struct {
+	Enabled       bool `json:"enabled"`
+	Window        int  `json:"window"`         // 统计的检测次数
+	HighThreshold int  `json:"high_threshold"` // 进入抖动的状态变化率（%）
+	LowThreshold  int  `json:"low_threshold"`  // 退出抖动的状态变化率（%）
 }
 
 // FullConfig 完整配置结构
 type FullConfig struct {
-	Ping    ProbeConfig   `json:"ping"`
-	Tcp     ProbeConfig   `json:"tcp"`
-	Http    ProbeConfig   `json:"http"`
-	Webhook WebhookConfig `json:"webhook"`
+	Ping    ProbeConfig           `json:"ping"`
+	Tcp     ProbeConfig           `json:"tcp"`
+	Http    ProbeConfig           `json:"http"`
+	Webhook WebhookConfig         `json:"webhook"`
+	Targets map[string]TargetMeta `json:"targets,omitempty"` // 按目标地址索引
+	Flap    FlapConfig            `json:"flap"`
 }
 
 var (
@@ -72,7 +116,12 @@ func Init(dbPath string) (*Storage, error) {
 		instance = &Storage{db: db}
 
 		// 创建配置表
-		err = instance.createTables()
+		if err = instance.createTables(); err != nil {
+			return
+		}
+
+		// 为升级前已有的配置补录初始版本
+		err = instance.ensureInitialRevision()
 	})
 
 	if err != nil {
@@ -102,13 +151,228 @@ func (s *Storage) createTables() error {
 		timeout INTEGER DEFAULT 5,
 		webhook_url TEXT,
 		webhook_data TEXT,
+		webhook_secret TEXT DEFAULT '',
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		last_run_at DATETIME,
 		last_result TEXT
 	);
+
+	CREATE TABLE IF NOT EXISTS users (
+		username TEXT PRIMARY KEY,
+		password_hash TEXT NOT NULL,
+		role TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS api_tokens (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		role TEXT NOT NULL,
+		created_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		last_used_at DATETIME
+	);
+
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		actor TEXT NOT NULL,
+		source TEXT NOT NULL,
+		source_ip TEXT DEFAULT '',
+		method TEXT DEFAULT '',
+		endpoint TEXT NOT NULL,
+		action TEXT DEFAULT '',
+		before_json TEXT DEFAULT '',
+		after_json TEXT DEFAULT '',
+		diff_json TEXT DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+
+	CREATE TABLE IF NOT EXISTS config_revisions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		value TEXT NOT NULL,
+		author TEXT NOT NULL,
+		comment TEXT DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS webhook_outbox (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		alert_type TEXT NOT NULL,
+		target TEXT DEFAULT '',
+		url TEXT NOT NULL,
+		method TEXT NOT NULL,
+		headers TEXT DEFAULT '',
+		body TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER DEFAULT 0,
+		max_attempts INTEGER DEFAULT 1,
+		last_error TEXT DEFAULT '',
+		next_attempt_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_outbox_status ON webhook_outbox (status, next_attempt_at);
+
+	CREATE TABLE IF NOT EXISTS notify_channels (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		type TEXT NOT NULL,
+		enabled INTEGER DEFAULT 1,
+		url TEXT NOT NULL,
+		secret TEXT DEFAULT '',
+		method TEXT DEFAULT 'POST',
+		headers TEXT DEFAULT '',
+		template TEXT DEFAULT '',
+		email TEXT DEFAULT '',
+		timeout INTEGER DEFAULT 10,
+		retry INTEGER DEFAULT 3,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS escalation_policies (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		enabled INTEGER DEFAULT 1,
+		steps TEXT DEFAULT '[]',
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS incidents (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		target TEXT NOT NULL,
+		probe_type TEXT NOT NULL,
+		severity TEXT DEFAULT '',
+		tags TEXT DEFAULT '[]',
+		error TEXT DEFAULT '',
+		status TEXT NOT NULL,
+		policy_id TEXT DEFAULT '',
+		step INTEGER DEFAULT 0,
+		started_at TEXT NOT NULL,
+		acknowledged_at TEXT DEFAULT '',
+		acknowledged_by TEXT DEFAULT '',
+		resolved_at TEXT DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_incidents_status ON incidents (status);
+
+	CREATE TABLE IF NOT EXISTS incident_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		incident_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		step INTEGER DEFAULT 0,
+		channels TEXT DEFAULT '[]',
+		actor TEXT DEFAULT '',
+		message TEXT DEFAULT '',
+		created_at TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_incident_events_incident ON incident_events (incident_id);
+
+	CREATE TABLE IF NOT EXISTS notify_routes (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		enabled INTEGER DEFAULT 1,
+		tags TEXT DEFAULT '[]',
+		probe_types TEXT DEFAULT '[]',
+		alert_types TEXT DEFAULT '[]',
+		severities TEXT DEFAULT '[]',
+		channels TEXT DEFAULT '[]',
+		escalation TEXT DEFAULT '',
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS target_silences (
+		target TEXT PRIMARY KEY,
+		reason TEXT DEFAULT '',
+		created_by TEXT DEFAULT '',
+		created_at TEXT NOT NULL,
+		expires_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS maintenance_windows (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		targets TEXT DEFAULT '[]',
+		tags TEXT DEFAULT '[]',
+		start_at TEXT DEFAULT '',
+		end_at TEXT DEFAULT '',
+		cron TEXT DEFAULT '',
+		duration INTEGER DEFAULT 0,
+		comment TEXT DEFAULT '',
+		created_by TEXT DEFAULT '',
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS check_results (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		target TEXT NOT NULL,
+		probe_type TEXT NOT NULL,
+		success INTEGER DEFAULT 0,
+		latency_ms REAL DEFAULT 0,
+		resolved_ip TEXT DEFAULT '',
+		status_code INTEGER DEFAULT 0,
+		timings TEXT DEFAULT '{}',
+		rtt TEXT DEFAULT '',
+		packet_loss REAL DEFAULT 0,
+		error TEXT DEFAULT '',
+		error_category TEXT DEFAULT '',
+		metrics TEXT DEFAULT '',
+		checked_at TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_check_results_target ON check_results (target, id);
 	`
-	_, err := s.db.Exec(schema)
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+
+	// 升级前创建的表补充新增列
+	columns := []struct{ table, column, definition string }{
+		{"webhook_outbox", "channel", "TEXT DEFAULT ''"},
+		{"webhook_outbox", "timeout", "INTEGER DEFAULT 0"},
+		{"webhook_outbox", "format", "TEXT DEFAULT 'webhook'"},
+		{"webhook_outbox", "secret", "TEXT DEFAULT ''"},
+		{"notify_channels", "secret", "TEXT DEFAULT ''"},
+		{"notify_channels", "template", "TEXT DEFAULT ''"},
+		{"notify_channels", "email", "TEXT DEFAULT ''"},
+		{"schedule_tasks", "webhook_secret", "TEXT DEFAULT ''"},
+		{"notify_routes", "escalation", "TEXT DEFAULT ''"},
+	}
+	for _, c := range columns {
+		if err := s.addColumnIfMissing(c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumnIfMissing 表中不存在指定列时添加该列
+func (s *Storage) addColumnIfMissing(table, column, definition string) error {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	rows.Close()
+
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
 	return err
 }
 
@@ -120,26 +384,40 @@ func (s *Storage) Close() error {
 	return nil
 }
 
-// SaveConfig 保存完整配置
-func (s *Storage) SaveConfig(cfg *FullConfig) error {
+// SaveConfig 保存完整配置，同时记录一个新的配置版本，返回版本号
+func (s *Storage) SaveConfig(cfg *FullConfig, author, comment string) (int64, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	data, err := json.Marshal(cfg)
 	if err != nil {
-		return fmt.Errorf("序列化配置失败: %w", err)
+		return 0, fmt.Errorf("序列化配置失败: %w", err)
 	}
 
-	_, err = s.db.Exec(`
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
 		INSERT OR REPLACE INTO config (key, value, updated_at) 
 		VALUES ('main_config', ?, CURRENT_TIMESTAMP)
 	`, string(data))
+	if err != nil {
+		return 0, fmt.Errorf("保存配置到 SQLite 失败: %w", err)
+	}
 
+	revision, err := insertConfigRevision(tx, string(data), author, comment)
 	if err != nil {
-		return fmt.Errorf("保存配置到 SQLite 失败: %w", err)
+		return 0, err
 	}
 
-	return nil
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("提交配置失败: %w", err)
+	}
+
+	return revision, nil
 }
 
 // LoadConfig 加载完整配置
@@ -184,6 +462,7 @@ func GetDefaultConfig() *FullConfig {
 			Enabled:          true,
 			Frequency:        30,
 			FailCount:        3,
+			RecoveryCount:    1,
 			Timeout:          5,
 			Retry:            3,
 			RemoteUpdateFreq: 60,
@@ -193,6 +472,7 @@ func GetDefaultConfig() *FullConfig {
 			Enabled:          false,
 			Frequency:        30,
 			FailCount:        3,
+			RecoveryCount:    1,
 			Timeout:          5,
 			Retry:            3,
 			RemoteUpdateFreq: 60,
@@ -202,6 +482,7 @@ func GetDefaultConfig() *FullConfig {
 			Enabled:          false,
 			Frequency:        30,
 			FailCount:        3,
+			RecoveryCount:    1,
 			Timeout:          10,
 			Retry:            3,
 			RemoteUpdateFreq: 60,
@@ -213,25 +494,31 @@ func GetDefaultConfig() *FullConfig {
 			Timeout: 10,
 			Headers: make(map[string]string),
 		},
+		Flap: FlapConfig{
+			Window:        20,
+			HighThreshold: 50,
+			LowThreshold:  25,
+		},
 	}
 }
 
 // ScheduleTask 定时任务结构（存储用）
 type ScheduleTask struct {
-	ID          string            `json:"id"`
-	Name        string            `json:"name"`
-	Enabled     bool              `json:"enabled"`
-	Cron        string            `json:"cron"`
-	CheckType   string            `json:"check_type"`
-	Target      string            `json:"target"`
-	Port        int               `json:"port"`
-	Timeout     int               `json:"timeout"`
-	WebhookURL  string            `json:"webhook_url"`
-	WebhookData map[string]string `json:"webhook_data"`
-	CreatedAt   string            `json:"created_at"`
-	UpdatedAt   string            `json:"updated_at"`
-	LastRunAt   *string           `json:"last_run_at"`
-	LastResult  string            `json:"last_result"`
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Enabled       bool              `json:"enabled"`
+	Cron          string            `json:"cron"`
+	CheckType     string            `json:"check_type"`
+	Target        string            `json:"target"`
+	Port          int               `json:"port"`
+	Timeout       int               `json:"timeout"`
+	WebhookURL    string            `json:"webhook_url"`
+	WebhookData   map[string]string `json:"webhook_data"`
+	WebhookSecret string            `json:"webhook_secret"`
+	CreatedAt     string            `json:"created_at"`
+	UpdatedAt     string            `json:"updated_at"`
+	LastRunAt     *string           `json:"last_run_at"`
+	LastResult    string            `json:"last_result"`
 }
 
 // SaveScheduleTask 保存定时任务
@@ -247,10 +534,10 @@ func (s *Storage) SaveScheduleTask(task *ScheduleTask) error {
 
 	_, err := s.db.Exec(`
 		INSERT OR REPLACE INTO schedule_tasks 
-		(id, name, enabled, cron, check_type, target, port, timeout, webhook_url, webhook_data, created_at, updated_at, last_run_at, last_result)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		(id, name, enabled, cron, check_type, target, port, timeout, webhook_url, webhook_data, webhook_secret, created_at, updated_at, last_run_at, last_result)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, task.ID, task.Name, task.Enabled, task.Cron, task.CheckType, task.Target, task.Port, task.Timeout,
-		task.WebhookURL, webhookData, task.CreatedAt, task.UpdatedAt, task.LastRunAt, task.LastResult)
+		task.WebhookURL, webhookData, task.WebhookSecret, task.CreatedAt, task.UpdatedAt, task.LastRunAt, task.LastResult)
 
 	if err != nil {
 		return fmt.Errorf("保存定时任务失败: %w", err)
@@ -270,10 +557,10 @@ func (s *Storage) GetScheduleTask(id string) (*ScheduleTask, error) {
 	var lastRunAt sql.NullString
 
 	err := s.db.QueryRow(`
-		SELECT id, name, enabled, cron, check_type, target, port, timeout, webhook_url, webhook_data, created_at, updated_at, last_run_at, last_result
+		SELECT id, name, enabled, cron, check_type, target, port, timeout, webhook_url, webhook_data, webhook_secret, created_at, updated_at, last_run_at, last_result
 		FROM schedule_tasks WHERE id = ?
 	`, id).Scan(&task.ID, &task.Name, &enabled, &task.Cron, &task.CheckType, &task.Target, &task.Port, &task.Timeout,
-		&task.WebhookURL, &webhookData, &task.CreatedAt, &task.UpdatedAt, &lastRunAt, &task.LastResult)
+		&task.WebhookURL, &webhookData, &task.WebhookSecret, &task.CreatedAt, &task.UpdatedAt, &lastRunAt, &task.LastResult)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -299,7 +586,7 @@ func (s *Storage) GetAllScheduleTasks() ([]*ScheduleTask, error) {
 	defer s.mu.RUnlock()
 
 	rows, err := s.db.Query(`
-		SELECT id, name, enabled, cron, check_type, target, port, timeout, webhook_url, webhook_data, created_at, updated_at, last_run_at, last_result
+		SELECT id, name, enabled, cron, check_type, target, port, timeout, webhook_url, webhook_data, webhook_secret, created_at, updated_at, last_run_at, last_result
 		FROM schedule_tasks ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -315,7 +602,7 @@ func (s *Storage) GetAllScheduleTasks() ([]*ScheduleTask, error) {
 		var lastRunAt sql.NullString
 
 		err := rows.Scan(&task.ID, &task.Name, &enabled, &task.Cron, &task.CheckType, &task.Target, &task.Port, &task.Timeout,
-			&task.WebhookURL, &webhookData, &task.CreatedAt, &task.UpdatedAt, &lastRunAt, &task.LastResult)
+			&task.WebhookURL, &webhookData, &task.WebhookSecret, &task.CreatedAt, &task.UpdatedAt, &lastRunAt, &task.LastResult)
 		if err != nil {
 			return nil, fmt.Errorf("读取定时任务失败: %w", err)
 		}
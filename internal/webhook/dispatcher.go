@@ -0,0 +1,218 @@
+package webhook
+
+import (
+	"dnsfailover/internal/config"
+	"dnsfailover/internal/logger"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 队列满时的处理策略
+const (
+	PolicyDrop  = "drop"  // 直接丢弃新告警
+	PolicyBlock = "block" // 阻塞等待队列空位（背压），超时后丢弃
+)
+
+// DispatchStats 告警分发统计
+type DispatchStats struct {
+	Workers      int     `json:"workers"`
+	Policy       string  `json:"policy"`
+	QueueDepth   int     `json:"queue_depth"`    // 当前排队数量
+	QueueSize    int     `json:"queue_size"`     // 队列容量
+	InFlight     int64   `json:"in_flight"`      // 正在投递的数量
+	Enqueued     int64   `json:"enqueued"`       // 累计入队
+	Dropped      int64   `json:"dropped"`        // 累计因队列满丢弃
+	Delivered    int64   `json:"delivered"`      // 累计首次投递成功
+	Failed       int64   `json:"failed"`         // 累计首次投递失败（已转入重试队列）
+	AvgLatencyMs float64 `json:"avg_latency_ms"` // 平均投递耗时
+}
+
+// Dispatcher 异步告警分发器
+// 探测协程只负责入队，由固定数量的工作协程调用 Client 投递，慢速的 Webhook 不会阻塞探测循环
+type Dispatcher struct {
+	client       *Client
+	queue        chan *Alert
+	queueSize    int
+	workers      int
+	policy       string
+	blockTimeout time.Duration
+	drainTimeout time.Duration
+	wg           sync.WaitGroup
+	mu           sync.RWMutex
+	running      bool
+
+	inFlight     atomic.Int64
+	enqueued     atomic.Int64
+	dropped      atomic.Int64
+	delivered    atomic.Int64
+	failed       atomic.Int64
+	totalLatency atomic.Int64 // 纳秒
+}
+
+// NewDispatcher 创建告警分发器
+func NewDispatcher(client *Client, cfg *config.DispatchConfig) *Dispatcher {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = 100
+	}
+	policy := cfg.Policy
+	if policy != PolicyBlock {
+		policy = PolicyDrop
+	}
+	blockTimeout := time.Duration(cfg.BlockTimeout) * time.Second
+	if blockTimeout <= 0 {
+		blockTimeout = 5 * time.Second
+	}
+	drainTimeout := time.Duration(cfg.DrainTimeout) * time.Second
+	if drainTimeout <= 0 {
+		drainTimeout = 10 * time.Second
+	}
+
+	return &Dispatcher{
+		client:       client,
+		queueSize:    queueSize,
+		workers:      workers,
+		policy:       policy,
+		blockTimeout: blockTimeout,
+		drainTimeout: drainTimeout,
+	}
+}
+
+// Start 启动工作协程
+func (d *Dispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.running {
+		return
+	}
+	if d.queue == nil {
+		d.queue = make(chan *Alert, d.queueSize)
+	}
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go d.worker(d.queue)
+	}
+	d.running = true
+	logger.Infof("[WEBHOOK] 告警分发器已启动 (%d 个工作协程, 队列容量 %d, 策略 %s)", d.workers, d.queueSize, d.policy)
+}
+
+// Stop 停止接收新告警，在排空时限内等待队列中的告警投递完成
+// 超时后不再等待：正在投递的告警已写入投递记录，重启后由重试队列继续投递
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	if !d.running {
+		d.mu.Unlock()
+		return
+	}
+	queue := d.queue
+	close(d.queue)
+	d.queue = nil
+	d.running = false
+	d.mu.Unlock()
+
+	if pending := len(queue) + int(d.inFlight.Load()); pending > 0 {
+		logger.Infof("[WEBHOOK] 等待 %d 条告警投递完成 (最长 %v)", pending, d.drainTimeout)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(d.drainTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		logger.Warnf("[WEBHOOK] 告警投递未在 %v 内完成，放弃等待 (排队 %d 条，投递中 %d 条)",
+			d.drainTimeout, len(queue), d.inFlight.Load())
+	}
+}
+
+// Dispatch 将告警加入分发队列，返回是否入队成功
+func (d *Dispatcher) Dispatch(alert *Alert) bool {
+	if alert.Timestamp == 0 {
+		alert.Timestamp = time.Now().Unix()
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if !d.running {
+		d.dropped.Add(1)
+		logger.Warnf("[WEBHOOK] 告警分发器未运行，丢弃告警: %s %s", alert.Type, alert.Target)
+		return false
+	}
+
+	select {
+	case d.queue <- alert:
+		d.enqueued.Add(1)
+		return true
+	default:
+	}
+
+	// 队列已满
+	if d.policy == PolicyBlock {
+		timer := time.NewTimer(d.blockTimeout)
+		defer timer.Stop()
+		select {
+		case d.queue <- alert:
+			d.enqueued.Add(1)
+			return true
+		case <-timer.C:
+		}
+	}
+
+	d.dropped.Add(1)
+	logger.Errorf("[WEBHOOK] ✗ 分发队列已满 (%d)，丢弃告警: %s %s", d.queueSize, alert.Type, alert.Target)
+	return false
+}
+
+// Stats 获取分发统计
+func (d *Dispatcher) Stats() DispatchStats {
+	d.mu.RLock()
+	depth := len(d.queue)
+	d.mu.RUnlock()
+
+	stats := DispatchStats{
+		Workers:    d.workers,
+		Policy:     d.policy,
+		QueueDepth: depth,
+		QueueSize:  d.queueSize,
+		InFlight:   d.inFlight.Load(),
+		Enqueued:   d.enqueued.Load(),
+		Dropped:    d.dropped.Load(),
+		Delivered:  d.delivered.Load(),
+		Failed:     d.failed.Load(),
+	}
+	if sent := stats.Delivered + stats.Failed; sent > 0 {
+		stats.AvgLatencyMs = float64(d.totalLatency.Load()) / float64(sent) / float64(time.Millisecond)
+	}
+	return stats
+}
+
+// worker 从队列取出告警并投递
+func (d *Dispatcher) worker(queue chan *Alert) {
+	defer d.wg.Done()
+
+	for alert := range queue {
+		d.inFlight.Add(1)
+		start := time.Now()
+		err := d.client.SendAlert(alert)
+		d.totalLatency.Add(int64(time.Since(start)))
+		d.inFlight.Add(-1)
+
+		if err != nil {
+			d.failed.Add(1)
+		} else {
+			d.delivered.Add(1)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/tls"
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/storage"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatEmail SMTP 邮件通道
+const FormatEmail = "email"
+
+// 邮件加密方式
+const (
+	EmailTLSStartTLS = "starttls" // 明文连接后升级（默认，端口 587）
+	EmailTLSImplicit = "tls"      // 隐式 TLS（端口 465）
+	EmailTLSNone     = "none"     // 不加密（端口 25，仅用于内网或测试）
+)
+
+// buildEmail 生成邮件投递地址与 MIME 邮件内容
+// 投递地址形如 smtp://user@host:port?tls=starttls&from=a@x.com&to=b@y.com,c@z.com，
+// 密码作为通道密钥单独保存，以便投递队列重试和重放
+func buildEmail(dest *Destination, alert *Alert) (string, []byte, error) {
+	cfg := dest.Email
+	if cfg == nil || cfg.Host == "" {
+		return "", nil, fmt.Errorf("邮件通道未配置 SMTP 服务器")
+	}
+	if cfg.From == "" || len(cfg.To) == 0 {
+		return "", nil, fmt.Errorf("邮件通道未配置发件人或收件人")
+	}
+
+	u := &url.URL{
+		Scheme: "smtp",
+		Host:   net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+	}
+	if cfg.Username != "" {
+		u.User = url.User(cfg.Username)
+	}
+	query := url.Values{}
+	query.Set("tls", cfg.TLS)
+	query.Set("from", cfg.From)
+	query.Set("to", strings.Join(cfg.To, ","))
+	u.RawQuery = query.Encode()
+
+	// 自定义模板渲染结果作为纯文本正文，否则同时生成纯文本与 HTML 正文
+	text, htmlBody := emailText(alert), emailHTML(alert)
+	if dest.Template != "" {
+		body, err := RenderTemplate(dest.Template, alert)
+		if err == nil {
+			text, htmlBody = string(body), ""
+		} else {
+			logger.Errorf("[WEBHOOK] 通道 %s %v，使用内置格式", dest.Channel, err)
+		}
+	}
+
+	msg, err := buildMIME(cfg.From, cfg.To, alertTitle(alert), text, htmlBody)
+	if err != nil {
+		return "", nil, err
+	}
+	return u.String(), msg, nil
+}
+
+// emailText 纯文本正文
+func emailText(alert *Alert) string {
+	var b strings.Builder
+	b.WriteString(alertTitle(alert) + "\n\n")
+	for _, f := range alertFields(alert) {
+		fmt.Fprintf(&b, "%s: %s\n", f[0], f[1])
+	}
+	if alert.Message != "" {
+		b.WriteString("\n" + alert.Message + "\n")
+	}
+	return b.String()
+}
+
+// emailHTML HTML 正文
+func emailHTML(alert *Alert) string {
+	color := "#2563eb"
+	switch alert.Type {
+	case AlertTypeDown:
+		color = "#dc2626"
+		if alert.Severity == SeverityWarning {
+			color = "#ea580c"
+		}
+	case AlertTypeRecovery:
+		color = "#16a34a"
+	case AlertTypeFlapping:
+		color = "#ea580c"
+	case AlertTypeDegraded:
+		color = "#ca8a04"
+		if alert.State == "up" {
+			color = "#16a34a"
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<h3 style="color: %s;">%s</h3>`, color, html.EscapeString(alertTitle(alert)))
+	b.WriteString(`<table cellpadding="6" style="border-collapse: collapse; font-size: 14px;">`)
+	for _, f := range alertFields(alert) {
+		fmt.Fprintf(&b, `<tr><td style="color: #6b7280;">%s</td><td>%s</td></tr>`,
+			html.EscapeString(f[0]), html.EscapeString(f[1]))
+	}
+	b.WriteString(`</table>`)
+	if alert.Message != "" {
+		fmt.Fprintf(&b, `<p style="color: #6b7280;">%s</p>`, html.EscapeString(alert.Message))
+	}
+	return b.String()
+}
+
+// buildMIME 生成 MIME 邮件（htmlBody 为空时只包含纯文本）
+func buildMIME(from string, to []string, subject, text, htmlBody string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if htmlBody == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("生成邮件失败: %w", err)
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("生成邮件失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeQuotedPrintable 以 quoted-printable 编码写入正文
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return fmt.Errorf("编码邮件正文失败: %w", err)
+	}
+	return qp.Close()
+}
+
+// sendMail 通过 SMTP 发送一封邮件（投递地址格式见 buildEmail）
+func sendMail(entry *storage.OutboxEntry) error {
+	u, err := url.Parse(entry.URL)
+	if err != nil {
+		return fmt.Errorf("解析邮件投递地址失败: %w", err)
+	}
+	query := u.Query()
+	host := u.Hostname()
+	from := query.Get("from")
+	to := strings.Split(query.Get("to"), ",")
+
+	timeout := time.Duration(entry.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	tlsConfig := &tls.Config{ServerName: host}
+
+	var conn net.Conn
+	if query.Get("tls") == EmailTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", u.Host, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", u.Host)
+	}
+	if err != nil {
+		return fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("SMTP 握手失败: %w", err)
+	}
+	defer client.Close()
+
+	if query.Get("tls") == EmailTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP 服务器不支持 STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS 失败: %w", err)
+		}
+	}
+
+	if u.User != nil && u.User.Username() != "" {
+		auth := smtp.PlainAuth("", u.User.Username(), entry.Secret, host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("设置发件人失败: %w", err)
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(strings.TrimSpace(rcpt)); err != nil {
+			return fmt.Errorf("设置收件人 %s 失败: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	if _, err := w.Write([]byte(entry.Body)); err != nil {
+		w.Close()
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	return client.Quit()
+}
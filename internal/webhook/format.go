@@ -0,0 +1,263 @@
+package webhook
+
+import (
+	"dnsfailover/internal/logger"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 通道类型（消息格式）
+const (
+	FormatWebhook  = "webhook"  // 原始告警 JSON
+	FormatDingTalk = "dingtalk" // 钉钉机器人
+	FormatFeishu   = "feishu"   // 飞书 / Lark 机器人
+	FormatSlack    = "slack"    // Slack Incoming Webhook
+	FormatTelegram = "telegram" // Telegram Bot API sendMessage
+	FormatWeCom    = "wecom"    // 企业微信群机器人
+)
+
+// formatter 将告警转换为平台原生的消息结构
+type formatter func(alert *Alert) interface{}
+
+var formatters = map[string]formatter{
+	FormatWebhook:  func(alert *Alert) interface{} { return alert },
+	FormatDingTalk: formatDingTalk,
+	FormatFeishu:   formatFeishu,
+	FormatSlack:    formatSlack,
+	FormatTelegram: formatTelegram,
+	FormatWeCom:    formatWeCom,
+}
+
+// buildPayload 生成请求 URL 与消息体
+// 配置了自定义模板时使用模板渲染结果作为消息体，否则按通道类型生成；
+// 模板渲染失败时记录错误并回退到内置格式，保证告警仍能送达
+func buildPayload(dest *Destination, alert *Alert) (string, []byte, error) {
+	if dest.Type == FormatEmail {
+		return buildEmail(dest, alert)
+	}
+
+	if dest.Template != "" {
+		body, err := RenderTemplate(dest.Template, alert)
+		if err == nil {
+			return dest.URL, body, nil
+		}
+		logger.Errorf("[WEBHOOK] 通道 %s %v，使用内置格式", dest.Channel, err)
+	}
+
+	format, rawURL := dest.Type, dest.URL
+	fn, ok := formatters[format]
+	if !ok {
+		fn = formatters[FormatWebhook]
+	}
+	payload := fn(alert)
+
+	// Telegram 的 chat_id 写在 URL 参数中，发送时移入消息体
+	if format == FormatTelegram {
+		msg := payload.(map[string]interface{})
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return "", nil, fmt.Errorf("解析 Telegram URL 失败: %w", err)
+		}
+		query := u.Query()
+		if chatID := query.Get("chat_id"); chatID != "" {
+			msg["chat_id"] = chatID
+			query.Del("chat_id")
+			u.RawQuery = query.Encode()
+			rawURL = u.String()
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("序列化告警失败: %w", err)
+	}
+	return rawURL, body, nil
+}
+
+// alertTitle 告警标题
+func alertTitle(alert *Alert) string {
+	switch alert.Type {
+	case AlertTypeDown:
+		if alert.Escalation > 0 {
+			return fmt.Sprintf("⏫ [升级 #%d] %s 故障未确认", alert.Escalation, alert.Target)
+		}
+		if alert.Reminder > 0 {
+			return fmt.Sprintf("⏰ [%s] %s 仍未恢复", strings.ToUpper(string(alert.Severity)), alert.Target)
+		}
+		return fmt.Sprintf("🔴 [%s] %s 故障", strings.ToUpper(string(alert.Severity)), alert.Target)
+	case AlertTypeRecovery:
+		return fmt.Sprintf("✅ %s 已恢复", alert.Target)
+	case AlertTypeFlapping:
+		return fmt.Sprintf("🟠 %s 状态抖动", alert.Target)
+	case AlertTypeStabilized:
+		return fmt.Sprintf("🔵 %s 已稳定（%s）", alert.Target, stateText(alert.State))
+	case AlertTypeDegraded:
+		if alert.State == "up" {
+			return fmt.Sprintf("✅ %s 性能已恢复", alert.Target)
+		}
+		return fmt.Sprintf("🟡 [%s] %s 性能降级", strings.ToUpper(string(alert.Severity)), alert.Target)
+	default:
+		return "🧪 告警通道测试"
+	}
+}
+
+// alertFields 告警详情（名称, 值）
+func alertFields(alert *Alert) [][2]string {
+	fields := [][2]string{
+		{"目标", alert.Target},
+		{"探针", alert.ProbeType},
+		{"级别", string(alert.Severity)},
+	}
+	if alert.Type == AlertTypeDown {
+		fields = append(fields, [2]string{"连续失败", fmt.Sprintf("%d 次（阈值 %d）", alert.FailCount, alert.Threshold)})
+		if alert.Error != "" {
+			fields = append(fields, [2]string{"错误", alert.Error})
+		}
+	}
+	if alert.Type == AlertTypeDegraded {
+		if alert.Error != "" {
+			fields = append(fields, [2]string{"原因", alert.Error})
+		}
+		fields = append(fields, [2]string{"延迟", fmt.Sprintf("%dms", alert.LatencyMs)})
+		if alert.PacketLoss > 0 {
+			fields = append(fields, [2]string{"丢包率", fmt.Sprintf("%.0f%%", alert.PacketLoss)})
+		}
+		if alert.JitterMs > 0 {
+			fields = append(fields, [2]string{"抖动", fmt.Sprintf("%dms", alert.JitterMs)})
+		}
+	}
+	if alert.Type == AlertTypeFlapping || alert.Type == AlertTypeStabilized {
+		fields = append(fields, [2]string{"状态变化率", fmt.Sprintf("%d%%", alert.FlapRate)})
+	}
+	if alert.State != "" {
+		fields = append(fields, [2]string{"当前状态", stateText(alert.State)})
+	}
+	if alert.Incident > 0 {
+		fields = append(fields, [2]string{"事件", fmt.Sprintf("#%d", alert.Incident)})
+	}
+	if alert.Duration > 0 {
+		fields = append(fields, [2]string{"持续时间", (time.Duration(alert.Duration) * time.Second).String()})
+	}
+	if len(alert.Tags) > 0 {
+		fields = append(fields, [2]string{"标签", strings.Join(alert.Tags, ", ")})
+	}
+	if alert.Hostname != "" {
+		fields = append(fields, [2]string{"主机", alert.Hostname})
+	}
+	fields = append(fields, [2]string{"时间", time.Unix(alert.Timestamp, 0).Format("2006-01-02 15:04:05")})
+	return fields
+}
+
+// markdownBody 通用 Markdown 列表（钉钉、飞书、企业微信）
+func markdownBody(alert *Alert) string {
+	var b strings.Builder
+	for _, f := range alertFields(alert) {
+		fmt.Fprintf(&b, "- **%s**: %s\n", f[0], f[1])
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// formatDingTalk 钉钉 markdown 消息
+func formatDingTalk(alert *Alert) interface{} {
+	title := alertTitle(alert)
+	return map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]interface{}{
+			"title": title,
+			"text":  "### " + title + "\n\n" + markdownBody(alert),
+		},
+	}
+}
+
+// formatFeishu 飞书消息卡片
+func formatFeishu(alert *Alert) interface{} {
+	template := "blue"
+	switch alert.Type {
+	case AlertTypeDown:
+		template = "red"
+		if alert.Severity == SeverityWarning {
+			template = "orange"
+		}
+	case AlertTypeRecovery:
+		template = "green"
+	case AlertTypeFlapping:
+		template = "orange"
+	case AlertTypeDegraded:
+		template = "yellow"
+		if alert.State == "up" {
+			template = "green"
+		}
+	}
+
+	return map[string]interface{}{
+		"msg_type": "interactive",
+		"card": map[string]interface{}{
+			"header": map[string]interface{}{
+				"title":    map[string]interface{}{"tag": "plain_text", "content": alertTitle(alert)},
+				"template": template,
+			},
+			"elements": []interface{}{
+				map[string]interface{}{
+					"tag":  "div",
+					"text": map[string]interface{}{"tag": "lark_md", "content": markdownBody(alert)},
+				},
+			},
+		},
+	}
+}
+
+// formatSlack Slack Block Kit 消息
+func formatSlack(alert *Alert) interface{} {
+	var b strings.Builder
+	for _, f := range alertFields(alert) {
+		fmt.Fprintf(&b, "*%s*: %s\n", f[0], f[1])
+	}
+
+	title := alertTitle(alert)
+	return map[string]interface{}{
+		"text": title,
+		"blocks": []interface{}{
+			map[string]interface{}{
+				"type": "header",
+				"text": map[string]interface{}{"type": "plain_text", "text": title},
+			},
+			map[string]interface{}{
+				"type": "section",
+				"text": map[string]interface{}{"type": "mrkdwn", "text": strings.TrimSuffix(b.String(), "\n")},
+			},
+		},
+	}
+}
+
+// formatTelegram Telegram HTML 消息
+func formatTelegram(alert *Alert) interface{} {
+	var b strings.Builder
+	b.WriteString("<b>" + html.EscapeString(alertTitle(alert)) + "</b>\n")
+	for _, f := range alertFields(alert) {
+		fmt.Fprintf(&b, "\n<b>%s</b>: %s", html.EscapeString(f[0]), html.EscapeString(f[1]))
+	}
+
+	return map[string]interface{}{
+		"text":       b.String(),
+		"parse_mode": "HTML",
+	}
+}
+
+// formatWeCom 企业微信 markdown 消息
+func formatWeCom(alert *Alert) interface{} {
+	color := "info"
+	if alert.Type == AlertTypeDown || alert.Type == AlertTypeFlapping || (alert.Type == AlertTypeDegraded && alert.State != "up") {
+		color = "warning"
+	}
+
+	return map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]interface{}{
+			"content": fmt.Sprintf("### <font color=\"%s\">%s</font>\n%s", color, alertTitle(alert), markdownBody(alert)),
+		},
+	}
+}
@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/storage"
+	"math/rand"
+	"time"
+)
+
+const (
+	// outboxPollInterval 检查待重试投递的间隔
+	outboxPollInterval = time.Second
+	// outboxBatchSize 每次处理的最大投递数量
+	outboxBatchSize = 20
+	// retryBaseDelay 首次重试的基础延迟，之后每次翻倍
+	retryBaseDelay = 2 * time.Second
+	// retryMaxDelay 重试延迟上限
+	retryMaxDelay = 10 * time.Minute
+	// deliveryLease 投递进行中的租约时间，进程在投递途中退出时，超过租约后重新投递
+	deliveryLease = 30 * time.Second
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// Start 启动后台重试循环（重启后继续投递未完成的记录）
+func (c *Client) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running {
+		return
+	}
+	c.stopChan = make(chan struct{})
+	c.running = true
+	go c.retryLoop(c.stopChan)
+}
+
+// Stop 停止后台重试循环
+func (c *Client) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running {
+		return
+	}
+	close(c.stopChan)
+	c.running = false
+}
+
+// retryLoop 定期投递到期的待重试记录
+func (c *Client) retryLoop(stop chan struct{}) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.processDue()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// processDue 投递所有到期的记录
+func (c *Client) processDue() {
+	store := storage.GetStorage()
+	if store == nil {
+		return
+	}
+
+	entries, err := store.GetDueOutbox(time.Now().Format(timeLayout), outboxBatchSize)
+	if err != nil {
+		logger.Warnf("[WEBHOOK] 读取投递队列失败: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		logger.Infof("[WEBHOOK] ↻ 重试投递 #%d %s (第 %d/%d 次)", entry.ID, entry.Target, entry.Attempts+1, entry.MaxAttempts)
+		c.attempt(entry)
+	}
+}
+
+// newOutboxEntry 按通道配置创建投递记录并写入队列
+// 写入失败时仍会尝试投递，只是无法在失败后重试
+func (c *Client) newOutboxEntry(dest *Destination, alertType, target, method, url string, body []byte) *storage.OutboxEntry {
+	now := time.Now()
+	entry := &storage.OutboxEntry{
+		Channel:       dest.Channel,
+		Format:        dest.Type,
+		Secret:        dest.credential(),
+		AlertType:     alertType,
+		Target:        target,
+		URL:           url,
+		Method:        method,
+		Headers:       dest.Headers,
+		Timeout:       dest.Timeout,
+		Body:          string(body),
+		Status:        storage.OutboxPending,
+		MaxAttempts:   dest.Retry + 1,
+		NextAttemptAt: now.Add(deliveryLease).Format(timeLayout),
+		CreatedAt:     now.Format(timeLayout),
+		UpdatedAt:     now.Format(timeLayout),
+	}
+
+	if store := storage.GetStorage(); store != nil {
+		if _, err := store.InsertOutbox(entry); err != nil {
+			logger.Warnf("[WEBHOOK] %v", err)
+		}
+	}
+	return entry
+}
+
+// attempt 投递一次并记录结果：成功、安排下一次重试或标记为失败
+func (c *Client) attempt(entry *storage.OutboxEntry) error {
+	err := c.deliver(entry)
+	entry.Attempts++
+
+	status := storage.OutboxDelivered
+	lastError := ""
+	next := time.Now()
+	if err != nil {
+		lastError = err.Error()
+		if entry.Attempts >= entry.MaxAttempts {
+			status = storage.OutboxFailed
+			logger.Errorf("[WEBHOOK] ✗ 投递失败，已达最大次数 %d: %s - %v", entry.MaxAttempts, entry.Target, err)
+		} else {
+			status = storage.OutboxPending
+			next = next.Add(backoff(entry.Attempts))
+			logger.Warnf("[WEBHOOK] ✗ 投递失败 (%d/%d)，%v 后重试: %v", entry.Attempts, entry.MaxAttempts, time.Until(next).Round(time.Second), err)
+		}
+	} else {
+		logger.Infof("[WEBHOOK] ✓ 告警发送成功: %s", entry.Target)
+	}
+
+	if store := storage.GetStorage(); store != nil && entry.ID > 0 {
+		if updateErr := store.UpdateOutboxAttempt(entry.ID, status, entry.Attempts, lastError, next.Format(timeLayout)); updateErr != nil {
+			logger.Warnf("[WEBHOOK] %v", updateErr)
+		}
+	}
+	return err
+}
+
+// backoff 第 n 次失败后的重试延迟：指数增长，并在 [50%, 100%] 区间内随机抖动，避免集中重试
+func backoff(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,140 @@
+package webhook
+
+import (
+	"dnsfailover/internal/logger"
+	"dnsfailover/internal/storage"
+	"strings"
+)
+
+// DefaultChannel 全局 Webhook 配置对应的通道名称
+const DefaultChannel = "default"
+
+// Destination 告警的一个投递目的地（通知通道或全局 Webhook）
+type Destination struct {
+	Channel  string
+	Type     string // 消息格式，见 Format* 常量
+	URL      string
+	Template string               // 自定义消息体模板（text/template），为空时使用内置格式
+	Email    *storage.EmailConfig // 邮件通道的 SMTP 配置
+	Secret   string
+	Method   string
+	Headers  map[string]string
+	Timeout  int
+	Retry    int
+}
+
+// credential 投递时使用的密钥：邮件通道为 SMTP 密码，其余为签名密钥
+func (d *Destination) credential() string {
+	if d.Type == FormatEmail && d.Email != nil {
+		return d.Email.Password
+	}
+	return d.Secret
+}
+
+// resolveDestinations 根据路由规则确定告警需要发送到的通道
+// 没有规则命中（或命中的通道均已禁用）时回退到全局 Webhook
+func (c *Client) resolveDestinations(alert *Alert) []*Destination {
+	var dests []*Destination
+
+	if store := storage.GetStorage(); store != nil {
+		rules, err := store.GetAllRouteRules()
+		if err != nil {
+			logger.Warnf("[WEBHOOK] 读取路由规则失败: %v", err)
+		}
+
+		matched := make(map[string]bool)
+		for _, rule := range rules {
+			if rule.Enabled && ruleMatches(rule, alert) {
+				for _, id := range rule.Channels {
+					matched[id] = true
+				}
+			}
+		}
+
+		if len(matched) > 0 {
+			channels, err := store.GetAllChannels()
+			if err != nil {
+				logger.Warnf("[WEBHOOK] 读取通知通道失败: %v", err)
+			}
+			for _, ch := range channels {
+				if ch.Enabled && matched[ch.ID] {
+					dests = append(dests, channelDestination(ch))
+				}
+			}
+		}
+	}
+
+	if cfg, _ := c.settings(); len(dests) == 0 && cfg.URL != "" {
+		dests = append(dests, &Destination{
+			Channel:  DefaultChannel,
+			Type:     cfg.Type,
+			URL:      cfg.URL,
+			Template: cfg.Template,
+			Secret:   cfg.Secret,
+			Method:   cfg.Method,
+			Headers:  cfg.Headers,
+			Timeout:  cfg.Timeout,
+			Retry:    cfg.Retry,
+		})
+	}
+	return dests
+}
+
+// EscalationPolicyFor 返回故障告警命中的第一条带升级策略的路由规则所引用的策略 ID
+func EscalationPolicyFor(alert *Alert) string {
+	store := storage.GetStorage()
+	if store == nil {
+		return ""
+	}
+
+	rules, err := store.GetAllRouteRules()
+	if err != nil {
+		logger.Warnf("[WEBHOOK] 读取路由规则失败: %v", err)
+		return ""
+	}
+	for _, rule := range rules {
+		if rule.Enabled && rule.Escalation != "" && ruleMatches(rule, alert) {
+			return rule.Escalation
+		}
+	}
+	return ""
+}
+
+// channelDestination 将通知通道转换为投递目的地
+func channelDestination(ch *storage.NotifyChannel) *Destination {
+	return &Destination{
+		Channel:  ch.Name,
+		Type:     ch.Type,
+		URL:      ch.URL,
+		Template: ch.Template,
+		Email:    ch.Email,
+		Secret:   ch.Secret,
+		Method:   ch.Method,
+		Headers:  ch.Headers,
+		Timeout:  ch.Timeout,
+		Retry:    ch.Retry,
+	}
+}
+
+// ruleMatches 判断告警是否满足路由规则的全部条件（条件为空表示不限制）
+func ruleMatches(rule *storage.RouteRule, alert *Alert) bool {
+	return matchAny(rule.Tags, alert.Tags) &&
+		matchAny(rule.ProbeTypes, []string{alert.ProbeType}) &&
+		matchAny(rule.AlertTypes, []string{string(alert.Type)}) &&
+		matchAny(rule.Severities, []string{string(alert.Severity)})
+}
+
+// matchAny 条件为空，或告警取值中任一项出现在条件中（不区分大小写）
+func matchAny(cond, values []string) bool {
+	if len(cond) == 0 {
+		return true
+	}
+	for _, c := range cond {
+		for _, v := range values {
+			if strings.EqualFold(c, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
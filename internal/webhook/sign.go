@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// signRequest 按平台要求为请求加签，返回实际发送的 URL 与消息体
+// 签名包含时间戳，因此在每次投递（包括重试和重放）时重新计算
+func signRequest(format, secret, rawURL string, body []byte, now time.Time) (string, []byte, error) {
+	if secret == "" {
+		return rawURL, body, nil
+	}
+
+	switch format {
+	case FormatDingTalk:
+		// 钉钉: timestamp 为毫秒，sign = Base64(HmacSHA256(key=secret, timestamp+"\n"+secret))，附加在 URL 参数中
+		timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "\n" + secret))
+		sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return "", nil, fmt.Errorf("解析钉钉 URL 失败: %w", err)
+		}
+		query := u.Query()
+		query.Set("timestamp", timestamp)
+		query.Set("sign", sign)
+		u.RawQuery = query.Encode()
+		return u.String(), body, nil
+
+	case FormatFeishu:
+		// 飞书: timestamp 为秒，sign = Base64(HmacSHA256(key=timestamp+"\n"+secret, 空消息))，写入消息体
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+		sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+		var msg map[string]interface{}
+		if err := json.Unmarshal(body, &msg); err != nil {
+			return "", nil, fmt.Errorf("解析飞书消息失败: %w", err)
+		}
+		msg["timestamp"] = timestamp
+		msg["sign"] = sign
+		signed, err := json.Marshal(msg)
+		if err != nil {
+			return "", nil, fmt.Errorf("序列化飞书消息失败: %w", err)
+		}
+		return rawURL, signed, nil
+	}
+
+	return rawURL, body, nil
+}
+
+// checkResponse 检查平台返回的业务错误码
+// 钉钉、飞书、企业微信在出错时仍返回 HTTP 200，需要解析响应体判断
+func checkResponse(format string, body []byte) error {
+	var resp struct {
+		ErrCode *int   `json:"errcode"` // 钉钉、企业微信
+		ErrMsg  string `json:"errmsg"`
+		Code    *int   `json:"code"` // 飞书
+		Msg     string `json:"msg"`
+	}
+
+	switch format {
+	case FormatDingTalk, FormatWeCom:
+		if json.Unmarshal(body, &resp) == nil && resp.ErrCode != nil && *resp.ErrCode != 0 {
+			return fmt.Errorf("平台返回错误: %d %s", *resp.ErrCode, resp.ErrMsg)
+		}
+	case FormatFeishu:
+		if json.Unmarshal(body, &resp) == nil && resp.Code != nil && *resp.Code != 0 {
+			return fmt.Errorf("平台返回错误: %d %s", *resp.Code, resp.Msg)
+		}
+	}
+	return nil
+}
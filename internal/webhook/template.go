@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"bytes"
+	"dnsfailover/internal/storage"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs 模板中可用的辅助函数
+var templateFuncs = template.FuncMap{
+	// json 输出 JSON 编码后的值（字符串会带引号并转义），用于拼接 JSON 消息体
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	// date 将 Unix 时间戳格式化为本地时间
+	"date": func(ts int64) string {
+		return time.Unix(ts, 0).Format("2006-01-02 15:04:05")
+	},
+	// duration 将秒数格式化为可读时长，如 5m30s
+	"duration": func(seconds int64) string {
+		return (time.Duration(seconds) * time.Second).String()
+	},
+}
+
+// ParseTemplate 解析告警消息模板
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("alert").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("模板解析失败: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderTemplate 使用告警字段渲染模板，返回请求体
+func RenderTemplate(text string, alert *Alert) ([]byte, error) {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alert); err != nil {
+		return nil, fmt.Errorf("模板渲染失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ValidateTemplate 使用示例告警试渲染模板，检查语法与字段引用是否正确
+func ValidateTemplate(text string) error {
+	if text == "" {
+		return nil
+	}
+	_, err := RenderTemplate(text, SampleAlert())
+	return err
+}
+
+// Preview 生成目的地的消息体预览；模板出错时返回错误而不回退到内置格式
+func Preview(dest *Destination, alert *Alert) ([]byte, error) {
+	alert.Message = alertMessage(alert)
+	if dest.Template != "" {
+		body, err := RenderTemplate(dest.Template, alert)
+		if err != nil || dest.Type != FormatEmail {
+			return body, err
+		}
+	}
+	if dest.Type == FormatEmail && dest.Email == nil {
+		dest.Email = &storage.EmailConfig{
+			Host: "smtp.example.com",
+			Port: 587,
+			TLS:  EmailTLSStartTLS,
+			From: "alert@example.com",
+			To:   []string{"ops@example.com"},
+		}
+	}
+	_, body, err := buildPayload(dest, alert)
+	return body, err
+}
+
+// SampleAlert 生成用于模板预览的示例告警
+func SampleAlert() *Alert {
+	alert := &Alert{
+		Type:      AlertTypeDown,
+		Severity:  SeverityCritical,
+		ProbeType: "tcp",
+		Target:    "example.com:443",
+		Tags:      []string{"team-a", "prod"},
+		FailCount: 3,
+		Threshold: 3,
+		Error:     "dial tcp 93.184.216.34:443: i/o timeout",
+		Duration:  90,
+		Hostname:  hostname(),
+		Timestamp: time.Now().Unix(),
+	}
+	alert.Message = alertMessage(alert)
+	return alert
+}
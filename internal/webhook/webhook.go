@@ -2,11 +2,16 @@ package webhook
 
 import (
 	"bytes"
+	"context"
 	"dnsfailover/internal/config"
 	"dnsfailover/internal/logger"
-	"encoding/json"
+	"dnsfailover/internal/signature"
+	"dnsfailover/internal/storage"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"sync"
 	"time"
 )
 
@@ -14,135 +19,319 @@ import (
 type AlertType string
 
 const (
-	AlertTypeDown     AlertType = "down"     // 目标不可达
-	AlertTypeRecovery AlertType = "recovery" // 目标恢复
+	AlertTypeDown       AlertType = "down"       // 目标不可达
+	AlertTypeRecovery   AlertType = "recovery"   // 目标恢复
+	AlertTypeFlapping   AlertType = "flapping"   // 目标状态抖动，暂停逐次告警
+	AlertTypeStabilized AlertType = "stabilized" // 目标停止抖动
+	AlertTypeDegraded   AlertType = "degraded"   // 目标可达但性能降级（State 为 up 时表示性能已恢复）
+	AlertTypeTest       AlertType = "test"       // 通道测试
+)
+
+// Severity 告警级别
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical" // 严重（默认）
+	SeverityWarning  Severity = "warning"  // 警告
+	SeverityInfo     Severity = "info"     // 提示
 )
 
 // Alert 告警信息
 type Alert struct {
-	Type      AlertType `json:"type"`       // 告警类型
-	ProbeType string    `json:"probe_type"` // 探针类型 (ping/tcp/http)
-	Target    string    `json:"target"`     // 检测目标
-	FailCount int       `json:"fail_count"` // 连续失败次数
-	Threshold int       `json:"threshold"`  // 失败阈值
-	Error     string    `json:"error"`      // 错误信息
-	Timestamp int64     `json:"timestamp"`  // 时间戳
-	Message   string    `json:"message"`    // 可读消息
+	Type       AlertType `json:"type"`                  // 告警类型
+	Severity   Severity  `json:"severity"`              // 告警级别
+	ProbeType  string    `json:"probe_type"`            // 探针类型 (ping/tcp/http)
+	Target     string    `json:"target"`                // 检测目标
+	Tags       []string  `json:"tags,omitempty"`        // 目标标签
+	FailCount  int       `json:"fail_count"`            // 连续失败次数
+	Threshold  int       `json:"threshold"`             // 失败阈值
+	Error      string    `json:"error"`                 // 错误信息
+	Duration   int64     `json:"duration"`              // 故障持续时间（秒，自首次失败起）
+	FlapRate   int       `json:"flap_rate,omitempty"`   // 状态变化率（%，仅抖动/稳定告警）
+	State      string    `json:"state,omitempty"`       // 稳定后的状态 up/down（仅稳定告警）；degraded/up（仅降级告警）
+	LatencyMs  int64     `json:"latency_ms,omitempty"`  // 延迟（毫秒，仅降级告警）
+	PacketLoss float64   `json:"packet_loss,omitempty"` // 丢包率（%，仅降级告警）
+	JitterMs   int64     `json:"jitter_ms,omitempty"`   // 抖动（毫秒，仅降级告警）
+	Incident   int64     `json:"incident_id,omitempty"` // 故障事件 ID
+	Escalation int       `json:"escalation,omitempty"`  // 升级步骤序号（仅升级通知）
+	Reminder   int       `json:"reminder,omitempty"`    // 持续故障提醒序号（仅仍未恢复提醒）
+	Hostname   string    `json:"hostname"`              // 发出告警的主机名
+	Timestamp  int64     `json:"timestamp"`             // 时间戳
+	Message    string    `json:"message"`               // 可读消息
 }
 
 // Client Webhook 客户端
 type Client struct {
-	cfg        *config.WebhookConfig
-	httpClient *http.Client
+	cfg        config.WebhookConfig // 全局 Webhook 配置的副本，受 mu 保护
+	httpClient *http.Client         // 配置变更时整体替换，不修改正在使用的客户端
+	stopChan   chan struct{}
+	running    bool
+	mu         sync.Mutex
 }
 
 // NewClient 创建 Webhook 客户端
 func NewClient(cfg *config.WebhookConfig) *Client {
-	timeout := time.Duration(cfg.Timeout) * time.Second
+	return &Client{
+		cfg:        *cfg,
+		httpClient: newHTTPClient(cfg.Timeout),
+	}
+}
+
+// newHTTPClient 按超时时间（秒）创建 HTTP 客户端，未配置时默认 10 秒
+func newHTTPClient(timeoutSeconds int) *http.Client {
+	timeout := time.Duration(timeoutSeconds) * time.Second
 	if timeout == 0 {
 		timeout = 10 * time.Second
 	}
+	return &http.Client{Timeout: timeout}
+}
 
-	return &Client{
-		cfg: cfg,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-	}
+// settings 获取当前的全局 Webhook 配置与 HTTP 客户端
+func (c *Client) settings() (config.WebhookConfig, *http.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cfg, c.httpClient
 }
 
 // SendAlert 发送告警
+// 按路由规则确定通知通道，每个通道的投递先写入持久化队列，然后立即尝试投递一次；
+// 失败时由后台按指数退避重试（最多 Retry 次）。返回第一个投递失败的错误
 func (c *Client) SendAlert(alert *Alert) error {
-	if c.cfg.URL == "" {
+	if alert.Severity == "" {
+		alert.Severity = SeverityCritical
+	}
+
+	dests := c.resolveDestinations(alert)
+	if len(dests) == 0 {
 		logger.Warn("[WEBHOOK] URL 未配置，无法发送告警通知")
 		return nil
 	}
+	return c.send(alert, dests)
+}
 
-	// 设置时间戳
-	alert.Timestamp = time.Now().Unix()
+// SendToChannels 将告警直接发送到指定的通知通道（不经过路由规则，用于告警升级）
+// 已禁用或不存在的通道会被跳过，返回实际发送的通道名称
+func (c *Client) SendToChannels(alert *Alert, channelIDs []string) ([]string, error) {
+	if alert.Severity == "" {
+		alert.Severity = SeverityCritical
+	}
 
-	// 生成可读消息
-	if alert.Type == AlertTypeDown {
-		alert.Message = fmt.Sprintf("[%s] %s 连续失败 %d 次（阈值: %d）: %s",
-			alert.ProbeType, alert.Target, alert.FailCount, alert.Threshold, alert.Error)
-	} else {
-		alert.Message = fmt.Sprintf("[%s] %s 已恢复正常",
-			alert.ProbeType, alert.Target)
+	store := storage.GetStorage()
+	if store == nil {
+		return nil, fmt.Errorf("数据库未初始化")
 	}
 
-	// 序列化 JSON
-	body, err := json.Marshal(alert)
+	var dests []*Destination
+	var names []string
+	for _, id := range channelIDs {
+		ch, err := store.GetChannel(id)
+		if err != nil {
+			return nil, err
+		}
+		if ch == nil || !ch.Enabled {
+			continue
+		}
+		dests = append(dests, channelDestination(ch))
+		names = append(names, ch.Name)
+	}
+	if len(dests) == 0 {
+		return nil, fmt.Errorf("没有可用的通知通道")
+	}
+	return names, c.send(alert, dests)
+}
+
+// send 向各目的地发送告警，返回第一个投递失败的错误
+func (c *Client) send(alert *Alert, dests []*Destination) error {
+	// 设置时间戳（经分发队列时为入队时间）
+	if alert.Timestamp == 0 {
+		alert.Timestamp = time.Now().Unix()
+	}
+	if alert.Hostname == "" {
+		alert.Hostname = hostname()
+	}
+	alert.Message = alertMessage(alert)
+
+	var firstErr error
+	for _, dest := range dests {
+		method := dest.Method
+		if method == "" {
+			method = "POST"
+		}
+
+		// 按通道类型或自定义模板生成消息
+		url, body, err := buildPayload(dest, alert)
+		if err != nil {
+			logger.Errorf("[WEBHOOK] ✗ 通道 %s: %v", dest.Channel, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		entry := c.newOutboxEntry(dest, string(alert.Type), alert.Target, method, url, body)
+
+		// 打印详细日志
+		logger.Infof("[WEBHOOK] ━━━━━━━━━━ 发送通知 ━━━━━━━━━━")
+		logger.Infof("[WEBHOOK] Channel: %s (%s)", dest.Channel, dest.Type)
+		logger.Infof("[WEBHOOK] URL: %s", dest.URL)
+		logger.Infof("[WEBHOOK] Method: %s", method)
+		logger.Infof("[WEBHOOK] Type: %s (%s)", alert.Type, alert.Severity)
+		logger.Infof("[WEBHOOK] Target: %s", alert.Target)
+		logger.Infof("[WEBHOOK] Message: %s", alert.Message)
+		logger.Infof("[WEBHOOK] Body: %s", string(body))
+
+		if err := c.attempt(entry); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// SendTest 向指定目的地直接发送一条测试消息（不经过投递队列）
+func SendTest(dest *Destination) error {
+	alert := &Alert{
+		Type:      AlertTypeTest,
+		Severity:  SeverityInfo,
+		ProbeType: "test",
+		Target:    "test.example.com",
+		Threshold: 3,
+		Hostname:  hostname(),
+		Timestamp: time.Now().Unix(),
+	}
+	alert.Message = alertMessage(alert)
+
+	url, body, err := buildPayload(dest, alert)
 	if err != nil {
-		return fmt.Errorf("序列化告警失败: %w", err)
+		return err
 	}
 
-	// 创建请求
-	method := c.cfg.Method
+	method := dest.Method
 	if method == "" {
 		method = "POST"
 	}
 
-	req, err := http.NewRequest(method, c.cfg.URL, bytes.NewReader(body))
+	client := &Client{httpClient: &http.Client{}}
+	return client.deliver(&storage.OutboxEntry{
+		Format:  dest.Type,
+		Secret:  dest.credential(),
+		URL:     url,
+		Method:  method,
+		Headers: dest.Headers,
+		Timeout: dest.Timeout,
+		Body:    string(body),
+	})
+}
+
+// hostname 获取本机主机名
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// alertMessage 生成可读消息
+func alertMessage(alert *Alert) string {
+	switch alert.Type {
+	case AlertTypeDown:
+		if alert.Escalation > 0 {
+			return fmt.Sprintf("[%s] %s 故障已持续 %v 仍未确认（升级步骤 #%d）: %s",
+				alert.ProbeType, alert.Target, time.Duration(alert.Duration)*time.Second, alert.Escalation, alert.Error)
+		}
+		if alert.Reminder > 0 {
+			return fmt.Sprintf("[%s] %s 仍未恢复，故障已持续 %v（第 %d 次提醒）: %s",
+				alert.ProbeType, alert.Target, time.Duration(alert.Duration)*time.Second, alert.Reminder, alert.Error)
+		}
+		return fmt.Sprintf("[%s] %s 连续失败 %d 次（阈值: %d）: %s",
+			alert.ProbeType, alert.Target, alert.FailCount, alert.Threshold, alert.Error)
+	case AlertTypeRecovery:
+		return fmt.Sprintf("[%s] %s 已恢复正常", alert.ProbeType, alert.Target)
+	case AlertTypeFlapping:
+		return fmt.Sprintf("[%s] %s 状态频繁变化（状态变化率 %d%%），暂停逐次告警直至稳定",
+			alert.ProbeType, alert.Target, alert.FlapRate)
+	case AlertTypeStabilized:
+		return fmt.Sprintf("[%s] %s 已停止抖动（状态变化率 %d%%），当前状态: %s",
+			alert.ProbeType, alert.Target, alert.FlapRate, stateText(alert.State))
+	case AlertTypeDegraded:
+		if alert.State == "up" {
+			return fmt.Sprintf("[%s] %s 性能已恢复正常（延迟 %dms）", alert.ProbeType, alert.Target, alert.LatencyMs)
+		}
+		return fmt.Sprintf("[%s] %s 性能降级（%s）: %s", alert.ProbeType, alert.Target, alert.Severity, alert.Error)
+	default:
+		return "这是一条 Webhook 测试消息"
+	}
+}
+
+// stateText 目标状态的可读文本
+func stateText(state string) string {
+	switch state {
+	case "down":
+		return "故障"
+	case "degraded":
+		return "降级"
+	}
+	return "正常"
+}
+
+// deliver 投递一次：邮件通道通过 SMTP 发送，其余发送 HTTP 请求（按平台要求加签，并检查平台返回的错误码）
+func (c *Client) deliver(entry *storage.OutboxEntry) error {
+	if entry.Format == FormatEmail {
+		return sendMail(entry)
+	}
+
+	url, body, err := signRequest(entry.Format, entry.Secret, entry.URL, []byte(entry.Body), time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if entry.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(entry.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, entry.Method, url, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("创建请求失败: %w", err)
 	}
 
 	// 设置 Headers
 	req.Header.Set("Content-Type", "application/json")
-	for key, value := range c.cfg.Headers {
+	for key, value := range entry.Headers {
 		req.Header.Set(key, value)
 	}
 
-	// 打印详细日志
-	logger.Infof("[WEBHOOK] ━━━━━━━━━━ 发送通知 ━━━━━━━━━━")
-	logger.Infof("[WEBHOOK] URL: %s", c.cfg.URL)
-	logger.Infof("[WEBHOOK] Method: %s", method)
-	logger.Infof("[WEBHOOK] Type: %s", alert.Type)
-	logger.Infof("[WEBHOOK] Target: %s", alert.Target)
-	logger.Infof("[WEBHOOK] Message: %s", alert.Message)
-	logger.Infof("[WEBHOOK] Body: %s", string(body))
+	// 配置了共享密钥时附带 HMAC 签名，供接收方校验来源
+	if entry.Secret != "" {
+		timestamp, sig := signature.Sign(entry.Secret, body, time.Now())
+		req.Header.Set(signature.HeaderTimestamp, timestamp)
+		req.Header.Set(signature.HeaderSignature, sig)
+	}
 
 	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	_, httpClient := c.settings()
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		logger.Errorf("[WEBHOOK] ✗ 发送失败: %v", err)
 		return fmt.Errorf("发送请求失败: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// 检查响应状态
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		logger.Warnf("[WEBHOOK] 响应状态码异常: %d", resp.StatusCode)
 		return fmt.Errorf("响应状态码异常: %d", resp.StatusCode)
 	}
 
-	logger.Infof("[WEBHOOK] ✓ 告警发送成功: %s (状态码: %d)", alert.Target, resp.StatusCode)
-	return nil
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	return checkResponse(entry.Format, respBody)
 }
 
-// SendDownAlert 发送故障告警
-func (c *Client) SendDownAlert(probeType, target string, failCount, threshold int, errMsg string) error {
-	return c.SendAlert(&Alert{
-		Type:      AlertTypeDown,
-		ProbeType: probeType,
-		Target:    target,
-		FailCount: failCount,
-		Threshold: threshold,
-		Error:     errMsg,
-	})
-}
-
-// SendRecoveryAlert 发送恢复告警
-func (c *Client) SendRecoveryAlert(probeType, target string) error {
-	return c.SendAlert(&Alert{
-		Type:      AlertTypeRecovery,
-		ProbeType: probeType,
-		Target:    target,
-	})
-}
-
-// UpdateConfig 更新配置
+// UpdateConfig 更新配置（保存副本，投递中的请求继续使用原客户端）
 func (c *Client) UpdateConfig(cfg *config.WebhookConfig) {
-	c.cfg = cfg
-	c.httpClient.Timeout = time.Duration(cfg.Timeout) * time.Second
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cfg = *cfg
+	c.httpClient = newHTTPClient(cfg.Timeout)
 }